@@ -0,0 +1,579 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+)
+
+const (
+	abiDirName         = "abi"
+	signaturesFileName = "signatures.txt"
+)
+
+// DecodedArg is a decoded abi argument
+type DecodedArg struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// DecodedCall is the decoded calldata and return data of a contract call
+type DecodedCall struct {
+	Method  string       `json:"method"`
+	Args    []DecodedArg `json:"args"`
+	Outputs []DecodedArg `json:"outputs,omitempty"`
+	Revert  string       `json:"revert,omitempty"`
+}
+
+// DecodedLog is a event log, decoded if the event is known
+type DecodedLog struct {
+	Address    common.Address `json:"address"`
+	NewAddress string         `json:"newAddress,omitempty"`
+	Event      string         `json:"event,omitempty"`
+	Args       []DecodedArg   `json:"args,omitempty"`
+	Topics     []common.Hash  `json:"topics,omitempty"`
+	Data       hexutil.Bytes  `json:"data,omitempty"`
+}
+
+// abiDecoder decodes contract calls with the user supplied ABIs, the ABIs
+// and signatures imported to the local abi directory, and the built-in
+// signatures, in this order.
+type abiDecoder struct {
+	abis    []abi.ABI
+	methods map[[4]byte][]abi.Method
+}
+
+func (cli *CLI) abiDir() string {
+	return filepath.Join(cli.walletPath, abiDirName)
+}
+
+// newABIDecoder loads the ABI files given by the user and all the local ABIs.
+func (cli *CLI) newABIDecoder(abiFiles []string) (*abiDecoder, error) {
+	d := &abiDecoder{
+		methods: make(map[[4]byte][]abi.Method),
+	}
+
+	for _, file := range abiFiles {
+		a, err := loadABIFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("load abi(%s) error: %v", file, err)
+		}
+		d.abis = append(d.abis, a)
+	}
+
+	dir := cli.abiDir()
+	if files, err := filepath.Glob(filepath.Join(dir, "*.json")); err == nil {
+		for _, file := range files {
+			a, err := loadABIFile(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: skip abi(%s): %v\n", file, err)
+				continue
+			}
+			d.abis = append(d.abis, a)
+		}
+	}
+	if a, err := abi.JSON(strings.NewReader(builtinABI)); err == nil {
+		d.abis = append(d.abis, a)
+	}
+
+	signatures := builtinSignatures
+	if f, err := os.Open(filepath.Join(dir, signaturesFileName)); err == nil {
+		lines, err := readLines(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(lines, signatures...)
+	}
+	for _, sig := range signatures {
+		method, err := parseSignature(sig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skip signature(%s): %v\n", sig, err)
+			continue
+		}
+		var id [4]byte
+		copy(id[:], method.ID)
+		d.methods[id] = append(d.methods[id], method)
+	}
+
+	return d, nil
+}
+
+func loadABIFile(file string) (abi.ABI, error) {
+	a, _, err := readABIFile(file)
+	return a, err
+}
+
+// readABIFile returns the parsed ABI and the ABI json of file, the truffle
+// and hardhat artifacts with the abi field are accepted as well.
+func readABIFile(file string) (abi.ABI, []byte, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return abi.ABI{}, nil, err
+	}
+	var artifact struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	if err := json.Unmarshal(b, &artifact); err == nil && len(artifact.ABI) > 0 {
+		b = artifact.ABI
+	}
+	a, err := abi.JSON(bytes.NewReader(b))
+	if err != nil {
+		return abi.ABI{}, nil, err
+	}
+	return a, b, nil
+}
+
+// lookup returns the candidate methods for the selector of data
+func (d *abiDecoder) lookup(data []byte) []abi.Method {
+	if len(data) < 4 {
+		return nil
+	}
+	var methods []abi.Method
+	for i := range d.abis {
+		if method, err := d.abis[i].MethodById(data[:4]); err == nil {
+			methods = append(methods, *method)
+		}
+	}
+	var id [4]byte
+	copy(id[:], data[:4])
+	return append(methods, d.methods[id]...)
+}
+
+// DecodeInput decodes the calldata, it returns nil if no known method
+// matches the data.
+func (d *abiDecoder) DecodeInput(data []byte) *DecodedCall {
+	call, _ := d.decode(data)
+	return call
+}
+
+// DecodeCall decodes the calldata and the return data or revert reason
+// of a call.
+func (d *abiDecoder) DecodeCall(input, output []byte) *DecodedCall {
+	call, method := d.decode(input)
+	if reason, err := abi.UnpackRevert(output); err == nil {
+		if call == nil {
+			call = &DecodedCall{}
+		}
+		call.Revert = reason
+		return call
+	}
+	if call == nil || len(method.Outputs) == 0 || len(output) == 0 {
+		return call
+	}
+	if values, err := method.Outputs.Unpack(output); err == nil {
+		call.Outputs = decodedArgs(method.Outputs, values)
+	}
+	return call
+}
+
+// DecodeLog decodes the event log with the known ABIs, the raw topics and
+// data are kept if no event matches.
+func (d *abiDecoder) DecodeLog(log *types.Log) *DecodedLog {
+	dl := &DecodedLog{Address: log.Address}
+	if len(log.Topics) > 0 {
+		for i := range d.abis {
+			event, err := d.abis[i].EventByID(log.Topics[0])
+			if err != nil {
+				continue
+			}
+			if args, ok := decodeEvent(event, log); ok {
+				dl.Event = event.Sig
+				dl.Args = args
+				return dl
+			}
+		}
+	}
+
+	dl.Topics = log.Topics
+	dl.Data = log.Data
+	return dl
+}
+
+// decodeEvent decodes the log as event, the number of indexed arguments
+// must match the topics, e.g. the ERC-20 and ERC-721 Transfer events
+// share the same ID.
+func decodeEvent(event *abi.Event, log *types.Log) ([]DecodedArg, bool) {
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if len(indexed) != len(log.Topics)-1 {
+		return nil, false
+	}
+	nonIndexed := event.Inputs.NonIndexed()
+	values, err := nonIndexed.Unpack(log.Data)
+	if err != nil {
+		return nil, false
+	}
+
+	args := make([]DecodedArg, 0, len(event.Inputs))
+	topic, value := 1, 0
+	for i, input := range event.Inputs {
+		name := input.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		arg := DecodedArg{Name: name, Type: input.Type.String()}
+		if input.Indexed {
+			// dynamic indexed values are only stored as the hash
+			m := make(map[string]interface{})
+			if err := abi.ParseTopicsIntoMap(m, abi.Arguments{input}, log.Topics[topic:topic+1]); err == nil {
+				arg.Value = formatABIValue(m[input.Name])
+			} else {
+				arg.Value = log.Topics[topic]
+			}
+			topic++
+		} else {
+			arg.Value = formatABIValue(values[value])
+			value++
+		}
+		args = append(args, arg)
+	}
+	return args, true
+}
+
+func (d *abiDecoder) decode(data []byte) (*DecodedCall, *abi.Method) {
+	for _, method := range d.lookup(data) {
+		values, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			continue
+		}
+		// make sure the data is exactly the encoding of the values, to
+		// choose the right one of the selector collisions
+		if packed, err := method.Inputs.Pack(values...); err != nil || !bytes.Equal(packed, data[4:]) {
+			continue
+		}
+		method := method
+		return &DecodedCall{
+			Method: method.Sig,
+			Args:   decodedArgs(method.Inputs, values),
+		}, &method
+	}
+	return nil, nil
+}
+
+func decodedArgs(arguments abi.Arguments, values []interface{}) []DecodedArg {
+	args := make([]DecodedArg, 0, len(values))
+	for i, value := range values {
+		name := arguments[i].Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		args = append(args, DecodedArg{
+			Name:  name,
+			Type:  arguments[i].Type.String(),
+			Value: formatABIValue(value),
+		})
+	}
+	return args
+}
+
+// formatABIValue converts an unpacked abi value to a JSON friendly value,
+// integers become decimal strings and byte arrays become hex strings.
+func formatABIValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case *big.Int:
+		return value.String()
+	case common.Address:
+		return value.String()
+	case common.Hash:
+		return value.String()
+	case []byte:
+		return hexutil.Encode(value)
+	case string, bool:
+		return value
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("%d", rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("%d", rv.Uint())
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		list := make([]interface{}, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			list[i] = formatABIValue(rv.Index(i).Interface())
+		}
+		return list
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		for i := 0; i < rv.NumField(); i++ {
+			m[rv.Type().Field(i).Name] = formatABIValue(rv.Field(i).Interface())
+		}
+		return m
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return formatABIValue(rv.Elem().Interface())
+	}
+
+	return v
+}
+
+// parseSignature parses a text function signature like
+// "transfer(address,uint256)" or "function transfer(address to, uint256 value)".
+func parseSignature(sig string) (abi.Method, error) {
+	sig = strings.TrimSpace(sig)
+	sig = strings.TrimPrefix(sig, "function ")
+	open := strings.Index(sig, "(")
+	if open <= 0 || !strings.HasSuffix(sig, ")") {
+		return abi.Method{}, errors.New("invalid signature")
+	}
+	name := strings.TrimSpace(sig[:open])
+
+	inputs := make([]interface{}, 0)
+	for i, arg := range splitTopLevel(sig[open+1 : len(sig)-1]) {
+		input, err := parseSignatureArg(arg, fmt.Sprintf("arg%d", i))
+		if err != nil {
+			return abi.Method{}, err
+		}
+		inputs = append(inputs, input)
+	}
+
+	b, err := json.Marshal([]interface{}{map[string]interface{}{
+		"type":    "function",
+		"name":    name,
+		"inputs":  inputs,
+		"outputs": []interface{}{},
+	}})
+	if err != nil {
+		return abi.Method{}, err
+	}
+	a, err := abi.JSON(bytes.NewReader(b))
+	if err != nil {
+		return abi.Method{}, err
+	}
+	for _, method := range a.Methods {
+		return method, nil
+	}
+	return abi.Method{}, errors.New("invalid signature")
+}
+
+// parseSignatureArg returns the JSON abi argument of a signature argument
+// like "uint256", "address to" or "(uint256,bytes)[]".
+func parseSignatureArg(arg, defaultName string) (map[string]interface{}, error) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return nil, errors.New("empty argument type")
+	}
+	name := defaultName
+	if strings.HasPrefix(arg, "(") {
+		depth := 0
+		end := -1
+		for i, c := range arg {
+			if c == '(' {
+				depth++
+			} else if c == ')' {
+				depth--
+				if depth == 0 {
+					end = i
+					break
+				}
+			}
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("unbalanced tuple %s", arg)
+		}
+		suffix := strings.Fields(arg[end+1:])
+		typ := "tuple"
+		if len(suffix) > 0 && strings.HasPrefix(suffix[0], "[") {
+			typ += suffix[0]
+			suffix = suffix[1:]
+		}
+		if len(suffix) > 0 {
+			name = suffix[len(suffix)-1]
+		}
+		components := make([]interface{}, 0)
+		for i, c := range splitTopLevel(arg[1:end]) {
+			component, err := parseSignatureArg(c, fmt.Sprintf("field%d", i))
+			if err != nil {
+				return nil, err
+			}
+			components = append(components, component)
+		}
+		return map[string]interface{}{"name": name, "type": typ, "components": components}, nil
+	}
+
+	fields := strings.Fields(arg)
+	if len(fields) > 1 {
+		name = fields[len(fields)-1]
+	}
+	return map[string]interface{}{"name": name, "type": fields[0]}, nil
+}
+
+// splitTopLevel splits s by the commas outside of parentheses
+func splitTopLevel(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func (cli *CLI) buildABICmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "abi [import|decode]",
+		Short:                 "Manage the local ABIs and function signatures to decode calldata",
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(cli.buildABIImportCmd())
+	cmd.AddCommand(cli.buildABIDecodeCmd())
+
+	return cmd
+}
+
+func (cli *CLI) buildABIImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "import <abi.json|signature>...",
+		Short:                 "Import ABI files or text function signatures to the local abi directory",
+		Args:                  cobra.MinimumNArgs(1),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := cli.abiDir()
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			var signatures []string
+			for _, arg := range args {
+				if strings.Contains(arg, "(") {
+					method, err := parseSignature(arg)
+					if err != nil {
+						fmt.Printf("Error: signature(%s) %v\n", arg, err)
+						return
+					}
+					signatures = append(signatures, method.Sig)
+					fmt.Printf("Imported %s %s\n", hexutil.Encode(method.ID), method.Sig)
+					continue
+				}
+
+				a, b, err := readABIFile(arg)
+				if err != nil {
+					fmt.Printf("Error: abi(%s) %v\n", arg, err)
+					return
+				}
+				out := filepath.Join(dir, filepath.Base(arg))
+				if !strings.HasSuffix(out, ".json") {
+					out += ".json"
+				}
+				if err := saveByteToFile(b, out); err != nil {
+					fmt.Println(err)
+					return
+				}
+				fmt.Printf("Imported %s with %d methods and %d events\n", out, len(a.Methods), len(a.Events))
+			}
+
+			if len(signatures) == 0 {
+				return
+			}
+			f, err := os.OpenFile(filepath.Join(dir, signaturesFileName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			defer f.Close()
+			w := bufio.NewWriter(f)
+			for _, sig := range signatures {
+				fmt.Fprintln(w, sig)
+			}
+			if err := w.Flush(); err != nil {
+				fmt.Println(err)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func (cli *CLI) buildABIDecodeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "decode <hexCalldata> [hexReturnData] [--abi abi.json]",
+		Short:                 "Decode calldata with the known ABIs and function signatures",
+		Args:                  cobra.MinimumNArgs(1),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			abiFiles, _ := cmd.Flags().GetStringSlice("abi")
+			decoder, err := cli.newABIDecoder(abiFiles)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			input := common.FromHex(args[0])
+			var output []byte
+			if len(args) > 1 {
+				output = common.FromHex(args[1])
+			}
+			call := decoder.DecodeCall(input, output)
+			if call == nil {
+				if len(input) >= 4 {
+					fmt.Printf("Unknown method %s\n", hexutil.Encode(input[:4]))
+				} else {
+					fmt.Println("Calldata too short")
+				}
+				return
+			}
+
+			out, err := json.MarshalIndent(call, "", " ")
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			fmt.Println(string(out))
+		},
+	}
+
+	addABIFlag(cmd)
+
+	return cmd
+}
+
+func addABIFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("abi", nil, "the ABI json `file` to decode calldata with, can be repeated")
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestABIDecode(t *testing.T) {
+	cli := NewCLI()
+
+	cli.TestCommand("abi decode 0xa9059cbb00000000000000000000000097549e368acafdcae786bb93d98379f1d1561a290000000000000000000000000000000000000000000000000de0b6b3a7640000")
+}
+
+func TestParseSignature(t *testing.T) {
+	for _, tt := range []struct {
+		sig, want, id string
+	}{
+		{"transfer(address,uint256)", "transfer(address,uint256)", "a9059cbb"},
+		{"function approve(address spender, uint256 value)", "approve(address,uint256)", "095ea7b3"},
+		{"multicall((address,bytes)[])", "multicall((address,bytes)[])", ""},
+	} {
+		method, err := parseSignature(tt.sig)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.sig, err)
+		}
+		if method.Sig != tt.want {
+			t.Errorf("%s: sig %s, want %s", tt.sig, method.Sig, tt.want)
+		}
+		if tt.id != "" && common.Bytes2Hex(method.ID) != tt.id {
+			t.Errorf("%s: id %x, want %s", tt.sig, method.ID, tt.id)
+		}
+	}
+	if _, err := parseSignature("transfer"); err == nil {
+		t.Error("want error for invalid signature")
+	}
+}
+
+func TestDecodeCall(t *testing.T) {
+	cli := NewCLI()
+	d, err := cli.newABIDecoder(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := common.FromHex("0xa9059cbb00000000000000000000000097549e368acafdcae786bb93d98379f1d1561a290000000000000000000000000000000000000000000000000de0b6b3a7640000")
+	output := common.FromHex("0x0000000000000000000000000000000000000000000000000000000000000001")
+	call := d.DecodeCall(input, output)
+	if call == nil {
+		t.Fatal("want decoded transfer")
+	}
+	if call.Method != "transfer(address,uint256)" || len(call.Args) != 2 || len(call.Outputs) != 1 {
+		t.Fatalf("unexpected call %+v", call)
+	}
+	if call.Args[1].Value != "1000000000000000000" {
+		t.Errorf("value %v, want 1000000000000000000", call.Args[1].Value)
+	}
+
+	if d.DecodeInput(common.FromHex("0x12345678")) != nil {
+		t.Error("want nil for unknown selector")
+	}
+}
+
+func TestDecodeLog(t *testing.T) {
+	cli := NewCLI()
+	d, err := cli.newABIDecoder(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transfer := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	from := common.HexToHash("0x00000000000000000000000097549e368acafdcae786bb93d98379f1d1561a29")
+	to := common.HexToHash("0x00000000000000000000000082a3a88bc9d6a70c4f3c66534566892eae0cad81")
+	log := &types.Log{
+		Topics: []common.Hash{transfer, from, to},
+		Data:   common.FromHex("0x0000000000000000000000000000000000000000000000000de0b6b3a7640000"),
+	}
+	dl := d.DecodeLog(log)
+	if dl.Event != "Transfer(address,address,uint256)" || len(dl.Args) != 3 {
+		t.Fatalf("unexpected log %+v", dl)
+	}
+	if dl.Args[1].Value != "0x82a3A88Bc9D6a70c4f3c66534566892Eae0Cad81" || dl.Args[2].Value != "1000000000000000000" {
+		t.Errorf("unexpected args %+v", dl.Args)
+	}
+
+	// ERC-721 Transfer has the same ID but the token ID is indexed
+	log = &types.Log{Topics: []common.Hash{transfer, from, to, common.BigToHash(common.Big1)}}
+	if dl := d.DecodeLog(log); dl.Event != "" || len(dl.Topics) != 4 {
+		t.Errorf("want raw log, got %+v", dl)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/ethereum/go-ethereum/accounts"
@@ -47,14 +48,8 @@ func (cli *CLI) buildAccountNewCmd() *cobra.Command {
 		Args:                  cobra.MinimumNArgs(0),
 		DisableFlagsInUseLine: true,
 		Run: func(cmd *cobra.Command, args []string) {
-			if cmd.Flags().Changed("light") {
-				light, _ := cmd.Flags().GetBool("light")
-				standard, _ := cmd.Flags().GetBool("standard")
-				if light && !standard {
-					cli.wallet = keystore.NewKeyStore(cli.walletPath,
-						keystore.LightScryptN, keystore.LightScryptP)
-				}
-			}
+			light, _ := cmd.Flags().GetBool("light")
+			standard, _ := cmd.Flags().GetBool("standard")
 
 			numOfNew, err := cmd.Flags().GetInt("numOfNew")
 			if err != nil {
@@ -63,7 +58,7 @@ func (cli *CLI) buildAccountNewCmd() *cobra.Command {
 
 			faucet, _ := cmd.Flags().GetBool("faucet")
 
-			aList, err := cli.createAccount(numOfNew)
+			aList, err := cli.createAccount(numOfNew, standard || !light)
 			if err != nil {
 				fmt.Println(err)
 				return
@@ -86,11 +81,20 @@ func (cli *CLI) buildAccountNewCmd() *cobra.Command {
 	return accountNewCmd
 }
 
-func (cli *CLI) createAccount(numOfNew int) ([]common.Address, error) {
-	if cli.wallet == nil {
-		cli.wallet = keystore.NewKeyStore(cli.walletPath,
+// scryptKeyStore returns the keystore of the wallet path to write the keys
+// with the standard or the light scrypt. The accounts are still unlocked in
+// the wallet of the session, which is not replaced.
+func (cli *CLI) scryptKeyStore(standard bool) *keystore.KeyStore {
+	if standard {
+		return keystore.NewKeyStore(cli.walletPath,
 			keystore.StandardScryptN, keystore.StandardScryptP)
 	}
+	return keystore.NewKeyStore(cli.walletPath,
+		keystore.LightScryptN, keystore.LightScryptP)
+}
+
+func (cli *CLI) createAccount(numOfNew int, standard bool) ([]common.Address, error) {
+	ks := cli.scryptKeyStore(standard)
 
 	walletPassword, err := getPassPhrase("Your new account is locked with a password. Please give a password. Do not forget this password.", true)
 	if err != nil {
@@ -104,7 +108,7 @@ func (cli *CLI) createAccount(numOfNew int) ([]common.Address, error) {
 
 	aList := make([]common.Address, 0)
 	for i := 0; i < numOfNew; i++ {
-		account, err := cli.wallet.NewAccount(walletPassword)
+		account, err := ks.NewAccount(walletPassword)
 		if err != nil {
 			return nil, fmt.Errorf("account error: %v", err)
 		}
@@ -217,15 +221,6 @@ func (cli *CLI) buildAccountUpdateCmd() *cobra.Command {
 		Args:                  cobra.MinimumNArgs(1),
 		DisableFlagsInUseLine: true,
 		Run: func(cmd *cobra.Command, args []string) {
-			standard, _ := cmd.Flags().GetBool("standard")
-			if standard {
-				cli.wallet = keystore.NewKeyStore(cli.walletPath,
-					keystore.StandardScryptN, keystore.StandardScryptP)
-			} else {
-				cli.wallet = keystore.NewKeyStore(cli.walletPath,
-					keystore.LightScryptN, keystore.LightScryptP)
-			}
-
 			addrStr := args[0]
 			var address common.Address
 			if !common.IsHexAddress(addrStr) {
@@ -239,11 +234,20 @@ func (cli *CLI) buildAccountUpdateCmd() *cobra.Command {
 				fmt.Println("Error: ", errRequiredFromAddress)
 				return
 			}
+			if err := cli.openWallet(true); err != nil {
+				fmt.Println(err)
+				return
+			}
 			if _, err := cli.wallet.Find(account); err != nil {
 				fmt.Printf("Error: %v (%s)\n", err, account.Address.String())
 				return
 			}
 
+			// check the password without unlocking the account in the
+			// wallet of the session, the key is written with the scrypt
+			standard, _ := cmd.Flags().GetBool("standard")
+			ks := cli.scryptKeyStore(standard)
+
 			var err error
 			walletPassword := cli.tran.Password
 			var trials int
@@ -254,8 +258,9 @@ func (cli *CLI) buildAccountUpdateCmd() *cobra.Command {
 				} else {
 					fmt.Println(prompt, "\nUse the the password has set")
 				}
-				err = cli.wallet.Unlock(account, walletPassword)
+				err = ks.Unlock(account, walletPassword)
 				if err == nil {
+					ks.Lock(account.Address)
 					break
 				}
 				walletPassword = ""
@@ -276,7 +281,7 @@ func (cli *CLI) buildAccountUpdateCmd() *cobra.Command {
 				return
 			}
 
-			if err := cli.wallet.Update(account, walletPassword, newWalletPassword); err != nil {
+			if err := ks.Update(account, walletPassword, newWalletPassword); err != nil {
 				fmt.Println("Error: udpate account error: ", err)
 				return
 			}
@@ -294,6 +299,29 @@ func addressToNew(chainID []byte, address common.Address) string {
 	return "NEW" + base58.CheckEncode(input, 0)
 }
 
+// toAddress converts the hex address or NEW address to address, the chainID
+// of NEW address is got from the connected node.
+func (cli *CLI) toAddress(addressStr string) (common.Address, error) {
+	if common.IsHexAddress(addressStr) {
+		return common.HexToAddress(addressStr), nil
+	}
+	if cli.blockchain != NewChain || !strings.HasPrefix(addressStr, "NEW") {
+		return common.Address{}, fmt.Errorf("invalid address %s", addressStr)
+	}
+	if err := cli.BuildClient(); err != nil {
+		return common.Address{}, err
+	}
+	chainID, err := cli.client.ChainID(context.Background())
+	if err != nil {
+		return common.Address{}, err
+	}
+	address, err := newToAddress(chainID.Bytes(), addressStr)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid address %s: %v", addressStr, err)
+	}
+	return address, nil
+}
+
 func newToAddress(chainID []byte, newAddress string) (common.Address, error) {
 	if newAddress[:3] != "NEW" {
 		return common.Address{}, errors.New("not NEW address")
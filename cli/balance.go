@@ -6,6 +6,7 @@ import (
 	"math/big"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/cobra"
@@ -13,7 +14,7 @@ import (
 
 func (cli *CLI) buildBalanceCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:                   fmt.Sprintf("balance [-u %s] [-n pending] [-s] [address1] [address2]...", strings.Join(UnitList, "|")),
+		Use:                   fmt.Sprintf("balance [-u %s] [-n pending] [--at time] [-s] [address1] [address2]...", strings.Join(UnitList, "|")),
 		Short:                 "Get balance of address",
 		Args:                  cobra.MinimumNArgs(0),
 		DisableFlagsInUseLine: true,
@@ -27,8 +28,11 @@ func (cli *CLI) buildBalanceCmd() *cobra.Command {
 	cmd.Flags().StringP("unit", "u", "", fmt.Sprintf("unit for balance. %s.", UnitString))
 	cmd.Flags().BoolP("safe", "s", false, "enable safe mode to check balance (force use the block 3 block heights less than the latest)")
 	cmd.Flags().StringP("number", "n", "latest", `the integer block number, or the string "latest", "earliest" or "pending"`)
+	cmd.Flags().String("at", "", "the RFC3339 time to get the balance of the last block not after it")
 	cmd.Flags().Bool("nosum", false, `disable show sum info`)
 
+	cmd.AddCommand(cli.buildBalanceHistoryCmd())
+
 	return cmd
 }
 
@@ -46,6 +50,30 @@ func (cli *CLI) getPendingBalance(address common.Address) (*big.Int, error) {
 	return cli.client.PendingBalanceAt(context.Background(), address)
 }
 
+// balanceAddresses returns the addresses of args, or all the wallet accounts
+// if no address is given.
+func (cli *CLI) balanceAddresses(args []string) ([]common.Address, error) {
+	var addressList []common.Address
+
+	if len(args) <= 0 {
+		if err := cli.openWallet(true); err != nil {
+			return nil, err
+		}
+
+		for _, account := range cli.wallet.Accounts() {
+			addressList = append(addressList, account.Address)
+		}
+
+		return addressList, nil
+	}
+
+	for _, addressStr := range args {
+		addressList = append(addressList, common.HexToAddress(addressStr))
+	}
+
+	return addressList, nil
+}
+
 func (cli *CLI) showBalance(cmd *cobra.Command, args []string, showSum bool) {
 	var err error
 
@@ -57,6 +85,16 @@ func (cli *CLI) showBalance(cmd *cobra.Command, args []string, showSum bool) {
 	}
 
 	safe, _ := cmd.Flags().GetBool("safe")
+	if at, _ := cmd.Flags().GetString("at"); at != "" {
+		if safe {
+			fmt.Println("Error: --at can not be used with -s")
+			return
+		}
+		if cmd.Flags().Changed("number") {
+			fmt.Println("Error: --at can not be used with -n")
+			return
+		}
+	}
 
 	pending := false
 	latest := true
@@ -96,22 +134,10 @@ func (cli *CLI) showBalance(cmd *cobra.Command, args []string, showSum bool) {
 		}
 	}
 
-	var addressList []common.Address
-
-	if len(args) <= 0 {
-		if err := cli.openWallet(true); err != nil {
-			fmt.Println(err)
-			return
-		}
-
-		for _, account := range cli.wallet.Accounts() {
-			addressList = append(addressList, account.Address)
-		}
-
-	} else {
-		for _, addressStr := range args {
-			addressList = append(addressList, common.HexToAddress(addressStr))
-		}
+	addressList, err := cli.balanceAddresses(args)
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
 	if err := cli.BuildClient(); err != nil {
@@ -133,6 +159,21 @@ func (cli *CLI) showBalance(cmd *cobra.Command, args []string, showSum bool) {
 		}
 		blockNumber = big.NewInt(0).Sub(latestHeader.Number, big.NewInt(3))
 		fmt.Printf("Safe mode enable, check balance at block height %s while the latest is %s\n", blockNumber.String(), latestHeader.Number.String())
+	} else if at, _ := cmd.Flags().GetString("at"); at != "" {
+		t, err := parseTime(at)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		header, err := newHeaderSearcher(cli.client.HeaderByNumber).at(ctx, t)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		blockNumber = header.Number
+		pending = false
+		fmt.Printf("Balance at block %s (%s)\n", blockNumber.String(),
+			time.Unix(int64(header.Time), 0).UTC().Format(time.RFC3339))
 	} else if !latest && !pending {
 		blockNumber = number
 	}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// balanceStep is the interval between two balance samples, months are
+// stepped in the calendar.
+type balanceStep struct {
+	months   int
+	duration time.Duration
+}
+
+// parseBalanceStep parses the step in months like 1mo, days like 7d, or
+// the duration like 12h.
+func parseBalanceStep(s string) (*balanceStep, error) {
+	var (
+		step *balanceStep
+		err  error
+	)
+	switch {
+	case strings.HasSuffix(s, "mo"):
+		var n int
+		n, err = strconv.Atoi(strings.TrimSuffix(s, "mo"))
+		step = &balanceStep{months: n}
+	case strings.HasSuffix(s, "d"):
+		var n int
+		n, err = strconv.Atoi(strings.TrimSuffix(s, "d"))
+		step = &balanceStep{duration: time.Duration(n) * 24 * time.Hour}
+	default:
+		var d time.Duration
+		d, err = time.ParseDuration(s)
+		step = &balanceStep{duration: d}
+	}
+	if err != nil || (step.months <= 0 && step.duration <= 0) {
+		return nil, fmt.Errorf("invalid step %s, use months like 1mo, days like 7d or the duration like 12h", s)
+	}
+	return step, nil
+}
+
+func isLastDayOfMonth(t time.Time) bool {
+	return t.AddDate(0, 0, 1).Day() == 1
+}
+
+// sampleTimes returns the times from the start to the end by the step, the
+// month steps from the last day of a month stay on the last days.
+func sampleTimes(from, to time.Time, step *balanceStep) []time.Time {
+	var times []time.Time
+	monthEnd := isLastDayOfMonth(from)
+	for i := 0; ; i++ {
+		var t time.Time
+		if step.months > 0 {
+			year, month, day := from.Date()
+			first := time.Date(year, month+time.Month(i*step.months), 1,
+				from.Hour(), from.Minute(), from.Second(), from.Nanosecond(), from.Location())
+			last := first.AddDate(0, 1, -1).Day()
+			if monthEnd || day > last {
+				day = last
+			}
+			t = first.AddDate(0, 0, day-1)
+		} else {
+			t = from.Add(time.Duration(i) * step.duration)
+		}
+		if t.After(to) {
+			return times
+		}
+		times = append(times, t)
+	}
+}
+
+func (cli *CLI) buildBalanceHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   fmt.Sprintf("history [address1] [address2]... --from <time> [--to now] [--step 1mo] [-u %s] [-o file]", strings.Join(UnitList, "|")),
+		Short:                 "Sample the balances of the addresses over the time range in csv, default all the wallet accounts",
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			unit, _ := cmd.Flags().GetString("unit")
+			if unit == "" {
+				unit = UnitETH
+			}
+			if !stringInSlice(unit, UnitList) {
+				fmt.Printf("Unit(%s) for invalid. %s.\n", unit, UnitString)
+				return
+			}
+
+			fromStr, _ := cmd.Flags().GetString("from")
+			if fromStr == "" {
+				fmt.Println("Error: the from time is required")
+				return
+			}
+			from, err := parseTime(fromStr)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			to := time.Now()
+			if toStr, _ := cmd.Flags().GetString("to"); toStr != "" && toStr != "now" {
+				if to, err = parseTime(toStr); err != nil {
+					fmt.Println(err)
+					return
+				}
+			}
+			stepStr, _ := cmd.Flags().GetString("step")
+			step, err := parseBalanceStep(stepStr)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			times := sampleTimes(from, to, step)
+			if len(times) == 0 {
+				fmt.Println("Error: the from time is after the to time")
+				return
+			}
+
+			addresses, err := cli.balanceAddresses(args)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx := context.Background()
+
+			var w io.Writer = os.Stdout
+			if output, _ := cmd.Flags().GetString("output"); output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				defer f.Close()
+				w = f
+			}
+			cw := csv.NewWriter(w)
+			defer cw.Flush()
+
+			record := []string{"time", "blockNumber", "blockTime"}
+			for _, address := range addresses {
+				record = append(record, address.String())
+			}
+			if err := cw.Write(record); err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			searcher := newHeaderSearcher(cli.client.HeaderByNumber)
+			for _, t := range times {
+				header, err := searcher.at(ctx, t)
+				if err == errTimeBeforeGenesis {
+					continue
+				} else if err != nil {
+					fmt.Println(err)
+					return
+				}
+				record := []string{
+					t.Format(time.RFC3339),
+					header.Number.String(),
+					time.Unix(int64(header.Time), 0).UTC().Format(time.RFC3339),
+				}
+				for _, address := range addresses {
+					balance, err := cli.client.BalanceAt(ctx, address, header.Number)
+					if err != nil {
+						fmt.Println("Balance error:", err)
+						return
+					}
+					record = append(record, getWeiAmountTextByUnit(balance, unit))
+				}
+				if err := cw.Write(record); err != nil {
+					fmt.Println(err)
+					return
+				}
+			}
+		},
+	}
+
+	cmd.Flags().String("from", "", "the RFC3339 time of the first sample")
+	cmd.Flags().String("to", "now", "the RFC3339 time not to sample after")
+	cmd.Flags().String("step", "1mo", "the interval between the samples, months like 1mo, days like 7d or the duration like 12h")
+	cmd.Flags().StringP("unit", "u", "", fmt.Sprintf("unit for balance. %s.", UnitString))
+	cmd.Flags().StringP("output", "o", "", "the csv file to write, default stdout")
+
+	return cmd
+}
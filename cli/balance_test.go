@@ -1,14 +1,82 @@
 package cli
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestBalance(t *testing.T) {
 	cli := NewCLI()
 
 	cli.TestCommand("balance --help")
 
-	cli.TestCommand("balance 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 -c ./../config.toml")
+	testGolden(t, "balance.json", "balance.golden",
+		"balance 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481",
+		"balance 0x01 002 003 0x004 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481",
+		"balance --at 2021-01-01T00:00:00Z 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481",
+		"balance 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 -n 1024 -u ISAAC",
+		"balance --at 2021-01-01T00:00:00Z -s 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481",
+		"balance --at 2021-01-01T00:00:00Z -n 1024 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481",
+	)
+}
+
+func TestBalanceHistory(t *testing.T) {
+	cli := NewCLI()
+
+	cli.TestCommand("balance history --help")
+
+	// the month-end samples stay on the last days of the months and the
+	// samples before the genesis are skipped, the samples after the latest
+	// block take it
+	testGolden(t, "balance_history.json", "balance_history.golden",
+		"balance history 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 0x0000000000000000000000000000000000000001 --from 2021-01-31T23:59:59Z --to 2021-05-31T23:59:59Z --step 1mo",
+		"balance history 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 --from 2020-12-25T00:00:00Z --to 2021-01-31T00:00:00Z --step 7d -u ISAAC",
+		"balance history 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 --from 2021-05-01T00:00:00Z --to 2021-04-01T00:00:00Z",
+	)
+}
 
-	cli.TestCommand("balance 0x01 002 003 0x004 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 -c ./../config.toml")
+func TestSampleTimes(t *testing.T) {
+	tests := []struct {
+		from, to, step string
+		want           []string
+	}{
+		{"2021-01-31T23:59:59Z", "2021-05-01T00:00:00Z", "1mo",
+			[]string{"2021-01-31T23:59:59Z", "2021-02-28T23:59:59Z", "2021-03-31T23:59:59Z", "2021-04-30T23:59:59Z"}},
+		{"2021-01-15T00:00:00Z", "2021-07-15T00:00:00Z", "3mo",
+			[]string{"2021-01-15T00:00:00Z", "2021-04-15T00:00:00Z", "2021-07-15T00:00:00Z"}},
+		{"2021-01-30T00:00:00Z", "2021-03-01T00:00:00Z", "1mo",
+			[]string{"2021-01-30T00:00:00Z", "2021-02-28T00:00:00Z"}},
+		{"2021-01-01T00:00:00Z", "2021-01-20T00:00:00Z", "7d",
+			[]string{"2021-01-01T00:00:00Z", "2021-01-08T00:00:00Z", "2021-01-15T00:00:00Z"}},
+		{"2021-01-01T00:00:00Z", "2021-01-01T12:00:00Z", "6h",
+			[]string{"2021-01-01T00:00:00Z", "2021-01-01T06:00:00Z", "2021-01-01T12:00:00Z"}},
+	}
+	for _, tt := range tests {
+		from, _ := parseTime(tt.from)
+		to, _ := parseTime(tt.to)
+		step, err := parseBalanceStep(tt.step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, sample := range sampleTimes(from, to, step) {
+			got = append(got, sample.Format(time.RFC3339))
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s to %s by %s: got %v, want %v", tt.from, tt.to, tt.step, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s to %s by %s: got %v, want %v", tt.from, tt.to, tt.step, got, tt.want)
+				break
+			}
+		}
+	}
 
+	for _, step := range []string{"0mo", "-1d", "1x", ""} {
+		if _, err := parseBalanceStep(step); err == nil {
+			t.Errorf("want error for step %q", step)
+		}
+	}
 }
@@ -10,8 +10,6 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -56,9 +54,11 @@ func (cli *CLI) buildBatchPayCmd() *cobra.Command {
 				fmt.Println("From address not set")
 				return
 			}
-			wallet := keystore.NewKeyStore(cli.walletPath,
-				keystore.StandardScryptN, keystore.StandardScryptP)
-			if !wallet.HasAddress(address) {
+			if err := cli.openWallet(true); err != nil {
+				fmt.Println(err)
+				return
+			}
+			if !cli.wallet.HasAddress(address) {
 				fmt.Println("From address not in wallet")
 				return
 			}
@@ -212,28 +212,20 @@ func (cli *CLI) buildBatchPayCmd() *cobra.Command {
 			fmt.Println("Total pay amount:", getWeiAmountTextUnitByUnit(totalAmount, UnitETH))
 			fmt.Println("Total gas amount:", getWeiAmountTextUnitByUnit(totalGas, UnitETH))
 
-			var walletPassword string
-			if cli.tran != nil {
-				walletPassword = cli.tran.Password
-			}
-			for trials := 0; trials <= 1; trials++ {
-				err = wallet.Unlock(accounts.Account{Address: address}, walletPassword)
-				if err == nil {
-					break
-				}
-				if trials >= 1 {
-					fmt.Printf("failed to unlock account %s (%v)\n", address.String(), err)
-					return
-
-				}
-				prompt := fmt.Sprintf("Unlocking account %s", address.String())
-				walletPassword, _ = getPassPhrase(prompt, false)
+			if err := cli.unlockWallet(accounts.Account{Address: address}); err != nil {
+				fmt.Printf("failed to unlock account %s (%v)\n", address.String(), err)
+				return
 			}
 
 			wait, _ := cmd.Flags().GetBool("wait")
+			opts, err := getWaitOptions(cmd)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
 			totalGasUsed := big.NewInt(0)
 			for _, tx := range txList {
-				signTx, err := wallet.SignTx(accounts.Account{Address: address}, tx, chainID)
+				signTx, err := cli.wallet.SignTx(accounts.Account{Address: address}, tx, chainID)
 				if err != nil {
 					fmt.Println(err)
 					return
@@ -251,17 +243,18 @@ func (cli *CLI) buildBatchPayCmd() *cobra.Command {
 					signTx.Nonce(), signTx.Hash().String())
 
 				if wait {
-					txr, err := bind.WaitMined(ctx, client, signTx)
+					txr, err := waitTx(ctx, client, signTx, opts)
 					if err != nil {
 						fmt.Println(err)
 						return
 					}
-					if txr.Status == 1 {
-						fmt.Printf("Succeed mined txID %s.\n", txr.TxHash.String())
-					} else {
-						fmt.Printf("Succeed mined txID %s but status failed.\n", txr.TxHash.String())
+					showWaitResult(txr)
+					fee, err := receiptFee(ctx, client, txr, signTx)
+					if err != nil {
+						fmt.Println(err)
+						return
 					}
-					totalGasUsed.Add(totalGasUsed, big.NewInt(0).Mul(tx.GasPrice(), big.NewInt(0).SetUint64(txr.GasUsed)))
+					totalGasUsed.Add(totalGasUsed, fee)
 				} else {
 					totalGasUsed.Add(totalGasUsed, big.NewInt(0).Mul(tx.GasPrice(), big.NewInt(0).SetUint64(tx.Gas())))
 				}
@@ -279,6 +272,7 @@ func (cli *CLI) buildBatchPayCmd() *cobra.Command {
 	cmd.Flags().Uint64P("price", "p", 1, fmt.Sprintf("the gasPrice used for each paid gas (unit in %s)", UnitWEI))
 	cmd.Flags().Uint64P("nonce", "n", 0, "the number of nonce")
 	cmd.Flags().Bool("wait", false, "wait for transaction to mined")
+	addWaitFlags(cmd)
 
 	return cmd
 }
@@ -3,13 +3,11 @@ package cli
 import (
 	"bytes"
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -59,6 +57,11 @@ func (cli *CLI) buildBlockCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(cli.buildBlockListCmd())
+	cmd.AddCommand(cli.buildBlockStatsCmd())
+	cmd.AddCommand(cli.buildBlockVotesCmd())
+	cmd.AddCommand(cli.buildBlockVerifyCmd())
+	cmd.AddCommand(cli.buildBlockFollowCmd())
+	cmd.AddCommand(cli.buildBlockAtCmd())
 
 	return cmd
 }
@@ -101,10 +104,6 @@ func (cli *CLI) buildBlockListCmd() *cobra.Command {
 			fmt.Println(latest.Number().String(), address.String())
 
 			showClique, _ := cmd.Flags().GetBool("clique")
-			var (
-				nonceAuthVote = hexutil.MustDecode("0xffffffffffffffff") // Magic nonce number to vote on adding a new signer
-				nonceDropVote = hexutil.MustDecode("0x0000000000000000") // Magic nonce number to vote on removing a signer.
-			)
 
 			for n := big.NewInt(1); n.Cmp(number) < 0; n.Add(n, big.NewInt(1)) {
 				block, err := client.BlockByNumber(ctx, big.NewInt(0).Sub(latest.Number(), n))
@@ -143,14 +142,19 @@ func (cli *CLI) buildBlockListCmd() *cobra.Command {
 }
 
 // sealHash returns the hash of a block prior to it being sealed.
-func sealHash(header *types.Header) (hash common.Hash) {
+func sealHash(header *types.Header) (hash common.Hash, err error) {
 	hasher := sha3.NewLegacyKeccak256()
-	encodeSigHeader(hasher, header)
+	if err := encodeSigHeader(hasher, header); err != nil {
+		return common.Hash{}, err
+	}
 	hasher.(crypto.KeccakState).Read(hash[:])
-	return hash
+	return hash, nil
 }
 
-func encodeSigHeader(w io.Writer, header *types.Header) {
+func encodeSigHeader(w io.Writer, header *types.Header) error {
+	if len(header.Extra) < crypto.SignatureLength {
+		return errMissingSignature
+	}
 	enc := []interface{}{
 		header.ParentHash,
 		header.UncleHash,
@@ -164,7 +168,7 @@ func encodeSigHeader(w io.Writer, header *types.Header) {
 		header.GasLimit,
 		header.GasUsed,
 		header.Time,
-		header.Extra[:len(header.Extra)-crypto.SignatureLength], // Yes, this will panic if extra is too short
+		header.Extra[:len(header.Extra)-crypto.SignatureLength],
 		header.MixDigest,
 		header.Nonce,
 	}
@@ -183,21 +187,23 @@ func encodeSigHeader(w io.Writer, header *types.Header) {
 	// if header.ParentBeaconRoot != nil {
 	// 	panic("unexpected parent beacon root value in clique")
 	// }
-	if err := rlp.Encode(w, enc); err != nil {
-		panic("can't encode: " + err.Error())
-	}
+	return rlp.Encode(w, enc)
 }
 
 // ecrecover extracts the Ethereum account address from a signed header.
 func ecrecover(header *types.Header) (common.Address, error) {
 	// Retrieve the signature from the header extra-data
-	if len(header.Extra) < 65 {
-		return common.Address{}, errors.New("extra-data 65 byte signature suffix missing")
+	if len(header.Extra) < extraSeal {
+		return common.Address{}, errMissingSignature
 	}
-	signature := header.Extra[len(header.Extra)-65:]
+	signature := header.Extra[len(header.Extra)-extraSeal:]
 
 	// Recover the public key and the Ethereum address
-	pubkey, err := crypto.Ecrecover(sealHash(header).Bytes(), signature)
+	hash, err := sealHash(header)
+	if err != nil {
+		return common.Address{}, err
+	}
+	pubkey, err := crypto.Ecrecover(hash.Bytes(), signature)
 	if err != nil {
 		return common.Address{}, err
 	}
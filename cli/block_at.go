@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+)
+
+var errTimeBeforeGenesis = errors.New("the time is before the genesis block")
+
+// headerSearcher finds the block at a time by binary searching the headers,
+// the fetched headers are cached for the following searches.
+type headerSearcher struct {
+	headerByNumber func(ctx context.Context, number *big.Int) (*types.Header, error)
+	headers        map[uint64]*types.Header
+	latest         *types.Header
+}
+
+func newHeaderSearcher(headerByNumber func(ctx context.Context, number *big.Int) (*types.Header, error)) *headerSearcher {
+	return &headerSearcher{
+		headerByNumber: headerByNumber,
+		headers:        make(map[uint64]*types.Header),
+	}
+}
+
+func (s *headerSearcher) header(ctx context.Context, number uint64) (*types.Header, error) {
+	if header, ok := s.headers[number]; ok {
+		return header, nil
+	}
+	header, err := s.headerByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, fmt.Errorf("get header %d error: %v", number, err)
+	}
+	s.headers[number] = header
+	return header, nil
+}
+
+// at returns the last block with the timestamp not after the time.
+func (s *headerSearcher) at(ctx context.Context, t time.Time) (*types.Header, error) {
+	if s.latest == nil {
+		latest, err := s.headerByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("get latest header error: %v", err)
+		}
+		s.latest = latest
+		s.headers[latest.Number.Uint64()] = latest
+	}
+	timestamp := t.Unix()
+	if timestamp >= int64(s.latest.Time) {
+		return s.latest, nil
+	}
+
+	lo, hi := uint64(0), s.latest.Number.Uint64()
+	genesis, err := s.header(ctx, lo)
+	if err != nil {
+		return nil, err
+	}
+	if timestamp < int64(genesis.Time) {
+		return nil, errTimeBeforeGenesis
+	}
+	// the block lo is not after the time and the block hi is after it
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		header, err := s.header(ctx, mid)
+		if err != nil {
+			return nil, err
+		}
+		if int64(header.Time) <= timestamp {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return s.header(ctx, lo)
+}
+
+// parseTime parses the RFC3339 time, or the date in UTC.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %s, use RFC3339 like 2006-01-02T15:04:05Z07:00", s)
+	}
+	return t, nil
+}
+
+func (cli *CLI) buildBlockAtCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "at <time>",
+		Short: "Show the last block not after the RFC3339 time",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			t, err := parseTime(args[0])
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			header, err := newHeaderSearcher(cli.client.HeaderByNumber).at(context.Background(), t)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			fmt.Printf("%d %s %s\n", header.Number.Uint64(), header.Hash().String(),
+				time.Unix(int64(header.Time), 0).UTC().Format(time.RFC3339))
+		},
+	}
+
+	return cmd
+}
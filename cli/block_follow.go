@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultFollowInterval = 2 * time.Second
+	followRecentHeaders   = 128 // the number of recent headers kept to detect reorgs
+)
+
+// headFollower prints the new heads and detects reorgs with the recent
+// canonical headers.
+type headFollower struct {
+	client *ethclient.Client
+	recent map[uint64]*types.Header
+	head   *types.Header
+}
+
+func newHeadFollower(client *ethclient.Client) *headFollower {
+	return &headFollower{
+		client: client,
+		recent: make(map[uint64]*types.Header),
+	}
+}
+
+// isSubscribable reports if the RPC URL supports subscriptions.
+func isSubscribable(rpcURL string) bool {
+	return strings.HasPrefix(rpcURL, "ws://") || strings.HasPrefix(rpcURL, "wss://") ||
+		!(strings.HasPrefix(rpcURL, "http://") || strings.HasPrefix(rpcURL, "https://"))
+}
+
+// subscribe follows the new heads with the newHeads subscription.
+func (f *headFollower) subscribe(ctx context.Context) error {
+	heads := make(chan *types.Header, 16)
+	sub, err := f.client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case header := <-heads:
+			f.handle(ctx, header)
+		case err := <-sub.Err():
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// poll follows the new heads by polling the latest header.
+func (f *headFollower) poll(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		latest, err := f.client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			fmt.Println("Get latest header error:", err)
+		} else if f.head == nil || latest.Number.Cmp(f.head.Number) < 0 {
+			f.handle(ctx, latest)
+		} else if number := f.head.Number.Uint64(); latest.Number.Uint64() > number {
+			// fetch the blocks skipped between two polls
+			for n := number + 1; n < latest.Number.Uint64(); n++ {
+				header, err := f.client.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+				if err != nil {
+					fmt.Printf("Get header %d error: %v\n", n, err)
+					break
+				}
+				f.handle(ctx, header)
+			}
+			f.handle(ctx, latest)
+		} else if latest.Hash() != f.head.Hash() {
+			f.handle(ctx, latest)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// handle prints the header and flags a reorg if it does not extend the
+// known canonical chain.
+func (f *headFollower) handle(ctx context.Context, header *types.Header) {
+	number := header.Number.Uint64()
+	if old, ok := f.recent[number]; ok && old.Hash() == header.Hash() {
+		return
+	}
+
+	parent, ok := f.recent[number-1]
+	if number > 0 && ok && parent.Hash() != header.ParentHash {
+		f.reorg(ctx, header)
+		parent = f.recent[number-1]
+	} else if old, ok := f.recent[number]; ok {
+		fmt.Printf("Reorg detected at block %d: depth 1, %s replaced by %s\n",
+			number, old.Hash().String(), header.Hash().String())
+	}
+	if number > 0 && (parent == nil || parent.Hash() != header.ParentHash) {
+		// the previous block is unknown or replaced by the reorg
+		parent = nil
+		if p, err := f.client.HeaderByHash(ctx, header.ParentHash); err == nil {
+			parent = p
+		}
+	}
+
+	signer := "unknown"
+	if address, err := ecrecover(header); err == nil {
+		signer = address.String()
+	}
+	txs := "?"
+	if count, err := f.client.TransactionCount(ctx, header.Hash()); err == nil {
+		txs = fmt.Sprintf("%d", count)
+	}
+	elapsed := "-"
+	if parent != nil {
+		elapsed = fmt.Sprintf("+%ds", int64(header.Time)-int64(parent.Time))
+	}
+	var usage float64
+	if header.GasLimit > 0 {
+		usage = float64(header.GasUsed) * 100 / float64(header.GasLimit)
+	}
+	fmt.Printf("%d %s signer %s txs %s gas %d (%.2f%%) %s\n",
+		number, header.Hash().String(), signer, txs, header.GasUsed, usage, elapsed)
+
+	// drop the headers of the old branch above the new head
+	for n := range f.recent {
+		if n > number || n+followRecentHeaders < number {
+			delete(f.recent, n)
+		}
+	}
+	f.recent[number] = header
+	f.head = header
+}
+
+// reorg walks back the new branch to the common ancestor with the known
+// chain and prints the reorg depth.
+func (f *headFollower) reorg(ctx context.Context, header *types.Header) {
+	var (
+		number = header.Number.Uint64()
+		hash   = header.ParentHash
+		depth  = uint64(1)
+	)
+	for n := number - 1; n > 0; n-- {
+		old, ok := f.recent[n]
+		if !ok {
+			fmt.Printf("Reorg detected at block %d: depth more than %d\n", number, depth-1)
+			return
+		}
+		if old.Hash() == hash {
+			break
+		}
+		newHeader, err := f.client.HeaderByHash(ctx, hash)
+		if err != nil {
+			fmt.Printf("Reorg detected at block %d: get header %s error: %v\n", number, hash.String(), err)
+			return
+		}
+		// replace the old block with the one of the new branch
+		f.recent[n] = newHeader
+		hash = newHeader.ParentHash
+		depth++
+	}
+	fmt.Printf("Reorg detected at block %d: depth %d, new parent %s\n", number, depth, header.ParentHash.String())
+}
+
+func (cli *CLI) buildBlockFollowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "follow [--poll] [--interval 2s]",
+		Short: "Follow the new blocks and flag the reorgs",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			f := newHeadFollower(cli.client)
+			poll, _ := cmd.Flags().GetBool("poll")
+			if !poll && isSubscribable(cli.rpcURL) {
+				err := f.subscribe(ctx)
+				if err == nil {
+					return
+				}
+				fmt.Println("Subscribe new heads error, fall back to polling:", err)
+			}
+
+			interval, _ := cmd.Flags().GetDuration("interval")
+			if interval <= 0 {
+				interval = defaultFollowInterval
+			}
+			if err := f.poll(ctx, interval); err != nil {
+				fmt.Println(err)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("poll", false, "poll the latest block even if the RPC supports subscriptions")
+	cmd.Flags().Duration("interval", defaultFollowInterval, "the interval to poll the latest block")
+
+	return cmd
+}
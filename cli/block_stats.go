@@ -0,0 +1,348 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultBlockRange  = 100
+	defaultConcurrency = 8
+)
+
+const (
+	extraVanity = 32                     // Fixed number of extra-data prefix bytes reserved for signer vanity
+	extraSeal   = crypto.SignatureLength // Fixed number of extra-data suffix bytes reserved for signer seal
+)
+
+var (
+	diffInTurn = big.NewInt(2) // Block difficulty for in-turn signatures
+	diffNoTurn = big.NewInt(1) // Block difficulty for out-of-turn signatures
+)
+
+// SignerStats is the sealing statistics of a clique signer
+type SignerStats struct {
+	Signer      common.Address `json:"signer"`
+	Sealed      uint64         `json:"sealed"`
+	InTurn      uint64         `json:"inTurn"`
+	OutOfTurn   uint64         `json:"outOfTurn"`
+	MissedTurns uint64         `json:"missedTurns"`
+	LastSealed  uint64         `json:"lastSealed,omitempty"`
+}
+
+// BlockStats is the sealing statistics of a block range
+type BlockStats struct {
+	From             uint64         `json:"from"`
+	To               uint64         `json:"to"`
+	Blocks           uint64         `json:"blocks"`
+	AverageBlockTime float64        `json:"averageBlockTime"`
+	Signers          []*SignerStats `json:"signers"`
+}
+
+func addBlockRangeFlags(cmd *cobra.Command) {
+	cmd.Flags().String("from", "", fmt.Sprintf("the first block number, default the last %d blocks", defaultBlockRange))
+	cmd.Flags().String("to", "latest", "the last block number")
+	cmd.Flags().Int("concurrency", defaultConcurrency, "the number of concurrent requests")
+}
+
+// getBlockRange returns the block range given by the --from and --to flags.
+func getBlockRange(ctx context.Context, cmd *cobra.Command, client *ethclient.Client) (uint64, uint64, error) {
+	toStr, _ := cmd.Flags().GetString("to")
+	var to uint64
+	if toStr == "" || toStr == "latest" {
+		latest, err := client.BlockNumber(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+		to = latest
+	} else {
+		n, err := strconv.ParseUint(toStr, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid to block number %s", toStr)
+		}
+		to = n
+	}
+
+	fromStr, _ := cmd.Flags().GetString("from")
+	var from uint64
+	if fromStr == "" {
+		if to >= defaultBlockRange {
+			from = to - defaultBlockRange + 1
+		}
+	} else {
+		n, err := strconv.ParseUint(fromStr, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid from block number %s", fromStr)
+		}
+		from = n
+	}
+	if from > to {
+		return 0, 0, errors.New("from block number is greater than to block number")
+	}
+
+	return from, to, nil
+}
+
+func getConcurrency(cmd *cobra.Command) int {
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return concurrency
+}
+
+// fetchHeaders gets the headers from block from to block to with
+// concurrency requests in flight, the result is ordered by number.
+func fetchHeaders(ctx context.Context, client *ethclient.Client, from, to uint64, concurrency int) ([]*types.Header, error) {
+	if from > to {
+		return nil, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	headers := make([]*types.Header, to-from+1)
+	numbers := make(chan uint64)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		fetchErr error
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range numbers {
+				header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+				if err != nil {
+					errOnce.Do(func() {
+						fetchErr = fmt.Errorf("get header %d error: %v", n, err)
+						cancel()
+					})
+					continue
+				}
+				headers[n-from] = header
+			}
+		}()
+	}
+
+	for n := from; n <= to; n++ {
+		select {
+		case numbers <- n:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(numbers)
+	wg.Wait()
+
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return headers, nil
+}
+
+// getCliqueSigners returns the authorized signers at the block via the
+// clique RPC, ordered the way clique picks the in-turn signer. The number
+// nil is the latest block.
+func getCliqueSigners(ctx context.Context, client *rpc.Client, number *big.Int) ([]common.Address, error) {
+	var signers []common.Address
+	if err := client.CallContext(ctx, &signers, "clique_getSigners", blockNumberArg(number)); err != nil {
+		return nil, err
+	}
+	sortAddresses(signers)
+	return signers, nil
+}
+
+func sortAddresses(addresses []common.Address) {
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+}
+
+func hexBlockNumber(number uint64) string {
+	return fmt.Sprintf("0x%x", number)
+}
+
+// blockNumberArg returns the block number argument of the RPC, nil for the
+// latest block.
+func blockNumberArg(number *big.Int) interface{} {
+	if number == nil {
+		return "latest"
+	}
+	return hexBlockNumber(number.Uint64())
+}
+
+// cliqueBlockStats counts the sealed, in-turn and out-of-turn blocks of the
+// signers. The turn of a block belongs to signers[number % len(signers)] of
+// the signers in effect for it, a turn is missed if the block is sealed out
+// of turn. The snapshot is at the parent of the first header and is moved
+// along the headers, the missed turns are not counted if it is nil.
+func cliqueBlockStats(headers []*types.Header, snap *cliqueSnapshot) (*BlockStats, error) {
+	if len(headers) == 0 {
+		return nil, errors.New("no block")
+	}
+	stats := &BlockStats{
+		From:   headers[0].Number.Uint64(),
+		To:     headers[len(headers)-1].Number.Uint64(),
+		Blocks: uint64(len(headers)),
+	}
+
+	bySigner := make(map[common.Address]*SignerStats)
+	get := func(signer common.Address) *SignerStats {
+		s, ok := bySigner[signer]
+		if !ok {
+			s = &SignerStats{Signer: signer}
+			bySigner[signer] = s
+		}
+		return s
+	}
+
+	for _, header := range headers {
+		number := header.Number.Uint64()
+		if number == 0 {
+			// the genesis block is not sealed
+			continue
+		}
+		signer, err := ecrecover(header)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %v", number, err)
+		}
+		var signers []common.Address
+		if snap != nil {
+			signers = snap.signers()
+			for _, signer := range signers {
+				get(signer)
+			}
+		}
+		s := get(signer)
+		s.Sealed++
+		s.LastSealed = number
+
+		if header.Difficulty.Cmp(diffInTurn) == 0 {
+			s.InTurn++
+		} else {
+			s.OutOfTurn++
+			if len(signers) > 0 {
+				get(signers[number%uint64(len(signers))]).MissedTurns++
+			}
+		}
+
+		if snap != nil {
+			if _, err := snap.apply(header); err != nil {
+				// restart from a checkpoint, or keep the signers over the
+				// header so the following turns are still counted
+				if checkpoint, err := newCliqueSnapshot(header, snap.Epoch); err == nil {
+					snap = checkpoint
+				} else {
+					snap.skip(header)
+				}
+			}
+		}
+	}
+
+	if len(headers) > 1 {
+		first, last := headers[0], headers[len(headers)-1]
+		stats.AverageBlockTime = float64(last.Time-first.Time) / float64(len(headers)-1)
+	}
+
+	for _, s := range bySigner {
+		stats.Signers = append(stats.Signers, s)
+	}
+	sort.Slice(stats.Signers, func(i, j int) bool {
+		return bytes.Compare(stats.Signers[i].Signer[:], stats.Signers[j].Signer[:]) < 0
+	})
+
+	return stats, nil
+}
+
+func (cli *CLI) buildBlockStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats [--from number] [--to number] [--epoch 30000] [--json]",
+		Short: "Show the clique sealing statistics of the signers",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx := context.Background()
+			from, to, err := getBlockRange(ctx, cmd, cli.client)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			headers, err := fetchHeaders(ctx, cli.client, from, to, getConcurrency(cmd))
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			// replay the votes to the parent of the first block, so each
+			// block is counted with the signers in effect for it
+			parent := from
+			if parent > 0 {
+				parent--
+			}
+			snap, _, err := replayCliqueVotes(ctx, cli.client, parent, getEpoch(cmd), getConcurrency(cmd))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Warning: replay clique votes error, the missed turns are not counted:", err)
+				snap = nil
+			}
+
+			stats, err := cliqueBlockStats(headers, snap)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			if jsonMode, _ := cmd.Flags().GetBool("json"); jsonMode {
+				b, err := json.MarshalIndent(stats, "", "  ")
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				fmt.Println(string(b))
+				return
+			}
+			showBlockStats(stats)
+		},
+	}
+
+	addBlockRangeFlags(cmd)
+	addEpochFlag(cmd)
+	cmd.Flags().Bool("json", false, "show the statistics in json")
+
+	return cmd
+}
+
+func showBlockStats(stats *BlockStats) {
+	fmt.Printf("Blocks %d to %d, %d blocks, average block time %s\n",
+		stats.From, stats.To, stats.Blocks,
+		time.Duration(stats.AverageBlockTime*float64(time.Second)).Round(time.Millisecond))
+	fmt.Printf("%-42s %8s %8s %11s %7s %11s\n", "Signer", "Sealed", "In-turn", "Out-of-turn", "Missed", "LastSealed")
+	for _, s := range stats.Signers {
+		fmt.Printf("%-42s %8d %8d %11d %7d %11d\n",
+			s.Signer.String(), s.Sealed, s.InTurn, s.OutOfTurn, s.MissedTurns, s.LastSealed)
+	}
+}
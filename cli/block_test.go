@@ -0,0 +1,442 @@
+package cli
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/newtonproject/newcommander/internal/rpctest"
+)
+
+// testSealHeader signs the header with key like a clique signer
+func testSealHeader(t *testing.T, header *types.Header, key *ecdsa.PrivateKey) {
+	if len(header.Extra) < extraVanity {
+		header.Extra = append(header.Extra, make([]byte, extraVanity-len(header.Extra))...)
+	}
+	header.Extra = append(header.Extra, make([]byte, extraSeal)...)
+	hash, err := sealHash(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.Sign(hash.Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+}
+
+func testSigners(n int) ([]*ecdsa.PrivateKey, []common.Address) {
+	keys := make([]*ecdsa.PrivateKey, n)
+	addresses := make([]common.Address, n)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addresses[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	// order the keys the way clique picks the in-turn signer
+	sortAddresses(addresses)
+	byAddress := make(map[common.Address]*ecdsa.PrivateKey)
+	for _, key := range keys {
+		byAddress[crypto.PubkeyToAddress(key.PublicKey)] = key
+	}
+	for i, address := range addresses {
+		keys[i] = byAddress[address]
+	}
+	return keys, addresses
+}
+
+func TestCliqueBlockStats(t *testing.T) {
+	keys, signers := testSigners(3)
+
+	// signer 1 misses its turn of block 4 and signer 2 of block 5
+	headers := testCliqueChain(t, keys, signers, []int{1, 2, 0, 2, 1, 0}, nil)
+	snap, err := newCliqueSnapshot(headers[0], defaultEpoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := cliqueBlockStats(headers[1:], snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Blocks != 6 || stats.AverageBlockTime != 3 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	testCheckSignerStats(t, stats, signers, []SignerStats{
+		{Sealed: 2, InTurn: 2},
+		{Sealed: 2, InTurn: 1, OutOfTurn: 1, MissedTurns: 1},
+		{Sealed: 2, InTurn: 1, OutOfTurn: 1, MissedTurns: 1},
+	})
+
+	// without the snapshot the missed turns are not counted
+	stats, err = cliqueBlockStats(headers[1:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range stats.Signers {
+		if s.MissedTurns != 0 {
+			t.Errorf("signer %s: want no missed turn, got %d", s.Signer.String(), s.MissedTurns)
+		}
+	}
+}
+
+func TestCliqueBlockStatsSignerChange(t *testing.T) {
+	keys, signers := testSigners(4)
+
+	// the signers 1 and 2 vote in signer 3 at block 4, the turns of the
+	// blocks 3 and 4 belong to the 3 signers and then to the 4 signers
+	auth := &cliqueVote{Address: signers[3], Authorize: true}
+	headers := testCliqueChain(t, keys, signers[:3], []int{1, 2, 1, 2, 3, 0}, []*cliqueVote{auth, nil, nil, auth, nil, nil})
+	snap, err := newCliqueSnapshot(headers[0], defaultEpoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := cliqueBlockStats(headers[1:], snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Signers) != 4 {
+		t.Fatalf("want 4 signers, got %v", snap.signers())
+	}
+	testCheckSignerStats(t, stats, signers, []SignerStats{
+		{Sealed: 1, OutOfTurn: 1, MissedTurns: 1},
+		{Sealed: 2, InTurn: 1, OutOfTurn: 1, MissedTurns: 2},
+		{Sealed: 2, InTurn: 1, OutOfTurn: 1, MissedTurns: 1},
+		{Sealed: 1, OutOfTurn: 1},
+	})
+}
+
+func testCheckSignerStats(t *testing.T, stats *BlockStats, signers []common.Address, want []SignerStats) {
+	if len(stats.Signers) != len(want) {
+		t.Fatalf("want %d signers, got %d", len(want), len(stats.Signers))
+	}
+	for i, want := range want {
+		s := stats.Signers[i]
+		if s.Signer != signers[i] || s.Sealed != want.Sealed || s.InTurn != want.InTurn ||
+			s.OutOfTurn != want.OutOfTurn || s.MissedTurns != want.MissedTurns {
+			t.Errorf("signer %d: got %+v, want %+v", i, s, want)
+		}
+	}
+}
+
+// testCliqueChain builds a clique header chain from a genesis checkpoint,
+// block i+1 is sealed by keys[sealers[i]] with the vote votes[i]. The
+// difficulty follows the signers in effect for the block.
+func testCliqueChain(t *testing.T, keys []*ecdsa.PrivateKey, signers []common.Address, sealers []int, votes []*cliqueVote) []*types.Header {
+	extra := make([]byte, extraVanity)
+	for _, signer := range signers {
+		extra = append(extra, signer[:]...)
+	}
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: diffNoTurn, Extra: append(extra, make([]byte, extraSeal)...)}
+	headers := []*types.Header{genesis}
+	snap, err := newCliqueSnapshot(genesis, defaultEpoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, sealer := range sealers {
+		header := &types.Header{
+			ParentHash: headers[i].Hash(),
+			Number:     big.NewInt(int64(i + 1)),
+			Difficulty: diffNoTurn,
+			Time:       uint64(i+1) * 3,
+		}
+		if snap.inturn(uint64(i+1), crypto.PubkeyToAddress(keys[sealer].PublicKey)) {
+			header.Difficulty = diffInTurn
+		}
+		if i < len(votes) && votes[i] != nil {
+			header.Coinbase = votes[i].Address
+			if votes[i].Authorize {
+				copy(header.Nonce[:], nonceAuthVote)
+			}
+		}
+		testSealHeader(t, header, keys[sealer])
+		headers = append(headers, header)
+		if _, err := snap.apply(header); err != nil {
+			snap.skip(header)
+		}
+	}
+	return headers
+}
+
+func TestCliqueSnapshotVotes(t *testing.T) {
+	keys, signers := testSigners(3)
+	newSigner := common.HexToAddress("0x97549e368acafdcae786bb93d98379f1d1561a29")
+
+	auth := &cliqueVote{Address: newSigner, Authorize: true}
+	headers := testCliqueChain(t, keys, signers, []int{0, 1, 2, 0}, []*cliqueVote{auth, nil, nil, nil})
+
+	snap, err := newCliqueSnapshot(headers[0], defaultEpoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, header := range headers[1:3] {
+		if _, err := snap.apply(header); err != nil {
+			t.Fatalf("block %d: %v", header.Number.Uint64(), err)
+		}
+	}
+	proposals := snap.proposals()
+	if len(proposals) != 1 || proposals[0].Votes != 1 || proposals[0].Threshold != 2 || !proposals[0].Authorize {
+		t.Fatalf("unexpected proposals %+v", proposals)
+	}
+
+	// the second vote passes the proposal
+	headers = testCliqueChain(t, keys, signers, []int{0, 1, 2, 0}, []*cliqueVote{auth, auth, nil, nil})
+	snap, _ = newCliqueSnapshot(headers[0], defaultEpoch)
+	for _, header := range headers[1:] {
+		if _, err := snap.apply(header); err != nil {
+			t.Fatalf("block %d: %v", header.Number.Uint64(), err)
+		}
+	}
+	if len(snap.Signers) != 4 || len(snap.proposals()) != 0 {
+		t.Fatalf("want 4 signers and no proposal, got %v %+v", snap.signers(), snap.proposals())
+	}
+
+	// a signer may not seal two blocks in a row
+	headers = testCliqueChain(t, keys, signers, []int{0, 0}, nil)
+	snap, _ = newCliqueSnapshot(headers[0], defaultEpoch)
+	snap.apply(headers[1])
+	if _, err := snap.apply(headers[2]); err != errRecentlySigned {
+		t.Fatalf("want %v, got %v", errRecentlySigned, err)
+	}
+}
+
+func TestVerifyHeader(t *testing.T) {
+	keys, signers := testSigners(3)
+	headers := testCliqueChain(t, keys, signers, []int{1, 2, 0, 2}, nil)
+
+	snap, err := newCliqueSnapshot(headers[0], defaultEpoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, header := range headers[1:] {
+		if err := verifyHeader(snap, headers[i], header); err != nil {
+			t.Fatalf("block %d: %v", header.Number.Uint64(), err)
+		}
+	}
+
+	// broken link, malformed extra-data and unauthorized signer
+	other, _ := crypto.GenerateKey()
+	unauthorized := &types.Header{ParentHash: headers[4].Hash(), Number: big.NewInt(5), Difficulty: diffNoTurn}
+	testSealHeader(t, unauthorized, other)
+	for _, tt := range []struct {
+		header *types.Header
+		want   error
+	}{
+		{&types.Header{ParentHash: common.Hash{1}, Number: big.NewInt(5), Difficulty: diffNoTurn}, errUnknownAncestor},
+		{&types.Header{ParentHash: headers[4].Hash(), Number: big.NewInt(5), Difficulty: diffNoTurn, Extra: []byte{1}}, errMissingVanity},
+		{&types.Header{ParentHash: headers[4].Hash(), Number: big.NewInt(5), Difficulty: diffNoTurn, Extra: make([]byte, extraVanity)}, errMissingSignature},
+		{unauthorized, errUnauthorizedSigner},
+	} {
+		snap, _ := newCliqueSnapshot(headers[0], defaultEpoch)
+		for i, header := range headers[1:] {
+			verifyHeader(snap, headers[i], header)
+		}
+		if err := verifyHeader(snap, headers[4], tt.header); !errors.Is(err, tt.want) {
+			t.Errorf("want %v, got %v", tt.want, err)
+		}
+	}
+
+	// sealHash must not panic on short extra-data
+	if _, err := sealHash(&types.Header{Number: big.NewInt(1)}); err == nil {
+		t.Error("want error for short extra-data")
+	}
+}
+
+func TestVerifyHeaders(t *testing.T) {
+	keys, signers := testSigners(2)
+	const epoch = 3
+	extra := make([]byte, extraVanity)
+	for _, signer := range signers {
+		extra = append(extra, signer[:]...)
+	}
+
+	// the in-turn signers seal the chain of the checkpoints 0, 3 and 6, the
+	// checkpoint 3 has the wrong difficulty
+	headers := []*types.Header{{Number: big.NewInt(0), Difficulty: diffNoTurn, Extra: append(append([]byte{}, extra...), make([]byte, extraSeal)...)}}
+	for n := int64(1); n <= 7; n++ {
+		header := &types.Header{ParentHash: headers[n-1].Hash(), Number: big.NewInt(n), Difficulty: diffInTurn, Time: uint64(n) * 3}
+		if n%epoch == 0 {
+			header.Extra = append([]byte{}, extra...)
+		}
+		if n == 3 {
+			header.Difficulty = diffNoTurn
+		}
+		testSealHeader(t, header, keys[n%2])
+		headers = append(headers, header)
+	}
+	var calls []*rpctest.Call
+	for _, header := range headers {
+		calls = append(calls, testCall(t, "eth_getBlockByNumber", header, hexutil.EncodeBig(header.Number)))
+	}
+	client, err := ethclient.Dial(rpctest.NewServer(t, calls).URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	for _, tt := range []struct {
+		from, to uint64
+		verified uint64
+		issues   []uint64
+	}{
+		{0, 2, 2, nil},
+		{3, 5, 3, []uint64{3}},
+		{4, 7, 4, nil},
+		{6, 6, 1, nil},
+	} {
+		result, err := verifyHeaders(context.Background(), client, tt.from, tt.to, epoch, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var issues []uint64
+		for _, issue := range result.Issues {
+			if !strings.Contains(issue.Error, errWrongDifficulty.Error()) {
+				t.Errorf("block %d: unexpected issue %s", issue.Number, issue.Error)
+			}
+			issues = append(issues, issue.Number)
+		}
+		if result.Verified != tt.verified || !reflect.DeepEqual(issues, tt.issues) {
+			t.Errorf("%d-%d: verified %d with issues %v, want %d with %v", tt.from, tt.to, result.Verified, issues, tt.verified, tt.issues)
+		}
+	}
+}
+
+func TestIsSubscribable(t *testing.T) {
+	for url, want := range map[string]bool{
+		"https://rpc1.newchain.newtonproject.org": false,
+		"http://127.0.0.1:8545":                   false,
+		"ws://127.0.0.1:8546":                     true,
+		"wss://example.org/ws":                    true,
+		"/data/newchain/geth.ipc":                 true,
+	} {
+		if got := isSubscribable(url); got != want {
+			t.Errorf("isSubscribable(%s) = %v, want %v", url, got, want)
+		}
+	}
+}
+
+func TestHeadFollowerReorg(t *testing.T) {
+	newHeader := func(parent *types.Header, extra string) *types.Header {
+		return &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, big.NewInt(1)),
+			Difficulty: diffInTurn,
+			Time:       parent.Time + 3,
+			Extra:      []byte(extra),
+		}
+	}
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: diffNoTurn}
+	a1 := newHeader(genesis, "a")
+	a2 := newHeader(a1, "a")
+	a3 := newHeader(a2, "a")
+	// the fork from a1, b3 has the same height as a3 but another parent
+	b2 := newHeader(a1, "b")
+	b3 := newHeader(b2, "b")
+	c3 := newHeader(b2, "c")
+	b4 := newHeader(b3, "b")
+
+	client, err := ethclient.Dial(rpctest.NewServer(t, []*rpctest.Call{
+		testCall(t, "eth_getBlockByHash", genesis, genesis.Hash()),
+		testCall(t, "eth_getBlockByHash", b2, b2.Hash()),
+		testCall(t, "eth_getBlockByHash", b3, b3.Hash()),
+		testCall(t, "eth_getBlockTransactionCountByHash", "0x0"),
+	}).URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	f := newHeadFollower(client)
+	out := captureStdout(t, func() {
+		for _, header := range []*types.Header{a1, a2, a3, a3, b3, c3, b4} {
+			f.handle(context.Background(), header)
+		}
+	})
+
+	want := []string{
+		fmt.Sprintf("1 %s signer unknown txs 0 gas 0 (0.00%%) +3s", a1.Hash().String()),
+		fmt.Sprintf("2 %s signer unknown txs 0 gas 0 (0.00%%) +3s", a2.Hash().String()),
+		fmt.Sprintf("3 %s signer unknown txs 0 gas 0 (0.00%%) +3s", a3.Hash().String()),
+		fmt.Sprintf("Reorg detected at block 3: depth 2, new parent %s", b2.Hash().String()),
+		fmt.Sprintf("3 %s signer unknown txs 0 gas 0 (0.00%%) +3s", b3.Hash().String()),
+		fmt.Sprintf("Reorg detected at block 3: depth 1, %s replaced by %s", b3.Hash().String(), c3.Hash().String()),
+		fmt.Sprintf("3 %s signer unknown txs 0 gas 0 (0.00%%) +3s", c3.Hash().String()),
+		fmt.Sprintf("Reorg detected at block 4: depth 2, new parent %s", b3.Hash().String()),
+		fmt.Sprintf("4 %s signer unknown txs 0 gas 0 (0.00%%) +3s", b4.Hash().String()),
+	}
+	if got := strings.Split(strings.TrimSpace(out), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if f.head != b4 || f.recent[3].Hash() != b3.Hash() || f.recent[2].Hash() != b2.Hash() {
+		t.Error("the recent headers do not follow the new branch")
+	}
+}
+
+func TestHeaderSearcher(t *testing.T) {
+	// blocks every 5 seconds from 1000 with a 100 seconds gap after block 50
+	var calls int
+	headerByNumber := func(ctx context.Context, number *big.Int) (*types.Header, error) {
+		calls++
+		n := uint64(100)
+		if number != nil {
+			n = number.Uint64()
+		}
+		time := 1000 + n*5
+		if n > 50 {
+			time += 100
+		}
+		return &types.Header{Number: new(big.Int).SetUint64(n), Time: time}, nil
+	}
+	s := newHeaderSearcher(headerByNumber)
+	ctx := context.Background()
+
+	tests := []struct {
+		time int64
+		want uint64
+	}{
+		{1000, 0},
+		{1004, 0},
+		{1005, 1},
+		{1250, 50},
+		{1300, 50}, // in the gap
+		{1355, 51},
+		{1600, 100},
+		{9999, 100},
+	}
+	for _, tt := range tests {
+		header, err := s.at(ctx, time.Unix(tt.time, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Number.Uint64() != tt.want {
+			t.Errorf("block at %d: got %d, want %d", tt.time, header.Number.Uint64(), tt.want)
+		}
+	}
+	if _, err := s.at(ctx, time.Unix(999, 0)); err != errTimeBeforeGenesis {
+		t.Errorf("error %v, want %v", err, errTimeBeforeGenesis)
+	}
+	if calls > 20 {
+		t.Errorf("%d header calls, want the headers cached", calls)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	testGolden(t, "block.json", "block.golden",
+		"block 2",
+		"block latest",
+		"block list 3",
+		"block at 2021-01-01T00:00:05Z",
+		"block at 2020-12-31T00:00:00Z",
+	)
+}
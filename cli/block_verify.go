@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+)
+
+// verifyBatchSize is the number of headers fetched and verified at a time
+const verifyBatchSize = 1024
+
+var (
+	errUnknownAncestor = errors.New("parent hash mismatch")
+	errWrongDifficulty = errors.New("wrong difficulty")
+)
+
+// HeaderIssue is a header that fails the verification
+type HeaderIssue struct {
+	Number uint64          `json:"number"`
+	Hash   common.Hash     `json:"hash"`
+	Signer *common.Address `json:"signer,omitempty"`
+	Error  string          `json:"error"`
+}
+
+// VerifyResult is the result of the header chain verification
+type VerifyResult struct {
+	From     uint64         `json:"from"`
+	To       uint64         `json:"to"`
+	Verified uint64         `json:"verified"`
+	Issues   []*HeaderIssue `json:"issues"`
+}
+
+// verifyHeaders verifies the headers from block from to block to. The
+// signers are replayed from the checkpoint before from, so the headers
+// before from are verified as well but the issues are not reported. A
+// checkpoint at from is verified against its parent, only the genesis is
+// not verified.
+func verifyHeaders(ctx context.Context, client *ethclient.Client, from, to, epoch uint64, concurrency int) (*VerifyResult, error) {
+	result := &VerifyResult{From: from, To: to}
+
+	number := from - from%epoch
+	if number == from && number > 0 {
+		number -= epoch
+	}
+	checkpoint, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, fmt.Errorf("get checkpoint header %d error: %v", number, err)
+	}
+	snap, err := newCliqueSnapshot(checkpoint, epoch)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint block %d: %v", number, err)
+	}
+
+	parent := checkpoint
+	for start := number + 1; start <= to; start += verifyBatchSize {
+		end := start + verifyBatchSize - 1
+		if end > to {
+			end = to
+		}
+		headers, err := fetchHeaders(ctx, client, start, end, concurrency)
+		if err != nil {
+			return nil, err
+		}
+		for _, header := range headers {
+			err := verifyHeader(snap, parent, header)
+			parent = header
+			if header.Number.Uint64() < from {
+				continue
+			}
+			result.Verified++
+			if err != nil {
+				issue := &HeaderIssue{
+					Number: header.Number.Uint64(),
+					Hash:   header.Hash(),
+					Error:  err.Error(),
+				}
+				if signer, err := ecrecover(header); err == nil {
+					issue.Signer = &signer
+				}
+				result.Issues = append(result.Issues, issue)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// verifyHeader checks the header links to the parent, the extra-data is well
+// formed, the signer is authorized and the difficulty matches the turn, then
+// applies the header to the snapshot.
+func verifyHeader(snap *cliqueSnapshot, parent, header *types.Header) error {
+	if header.ParentHash != parent.Hash() {
+		snap.skip(header)
+		return fmt.Errorf("%w: want %s, got %s", errUnknownAncestor, parent.Hash().String(), header.ParentHash.String())
+	}
+	if err := checkExtra(header, snap.Epoch); err != nil {
+		snap.skip(header)
+		return err
+	}
+	signer, err := ecrecover(header)
+	if err != nil {
+		snap.skip(header)
+		return err
+	}
+	inturn := snap.inturn(header.Number.Uint64(), signer)
+	if _, err := snap.apply(header); err != nil {
+		snap.skip(header)
+		return err
+	}
+	if header.Difficulty == nil {
+		return errWrongDifficulty
+	}
+	if inturn && header.Difficulty.Cmp(diffInTurn) != 0 {
+		return fmt.Errorf("%w: in-turn block with difficulty %s", errWrongDifficulty, header.Difficulty.String())
+	}
+	if !inturn && header.Difficulty.Cmp(diffNoTurn) != 0 {
+		return fmt.Errorf("%w: out-of-turn block with difficulty %s", errWrongDifficulty, header.Difficulty.String())
+	}
+	return nil
+}
+
+func (cli *CLI) buildBlockVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify [--from number] [--to number] [--epoch 30000] [--json]",
+		Short: "Verify the header chain links, seals and signer authorizations",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx := context.Background()
+			from, to, err := getBlockRange(ctx, cmd, cli.client)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			result, err := verifyHeaders(ctx, cli.client, from, to, getEpoch(cmd), getConcurrency(cmd))
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			if jsonMode, _ := cmd.Flags().GetBool("json"); jsonMode {
+				b, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				fmt.Println(string(b))
+				return
+			}
+			for _, issue := range result.Issues {
+				signer := "unknown signer"
+				if issue.Signer != nil {
+					signer = issue.Signer.String()
+				}
+				fmt.Printf("Block %d %s sealed by %s: %s\n", issue.Number, issue.Hash.String(), signer, issue.Error)
+			}
+			fmt.Printf("Verified %d headers from %d to %d, %d issues\n", result.Verified, result.From, result.To, len(result.Issues))
+		},
+	}
+
+	addBlockRangeFlags(cmd)
+	addEpochFlag(cmd)
+	cmd.Flags().Bool("json", false, "show the result in json")
+
+	return cmd
+}
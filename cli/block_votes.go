@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+)
+
+// CliqueProposal is a live proposal to authorize or drop a signer
+type CliqueProposal struct {
+	Address   common.Address `json:"address"`
+	Authorize bool           `json:"authorize"`
+	Votes     int            `json:"votes"`
+	Threshold int            `json:"threshold"`
+	Voters    []*cliqueVote  `json:"voters"`
+}
+
+// CliqueVotes is the clique voting state replayed from the last checkpoint
+type CliqueVotes struct {
+	Checkpoint        uint64            `json:"checkpoint"`
+	CheckpointSigners []common.Address  `json:"checkpointSigners"`
+	Number            uint64            `json:"number"`
+	Hash              common.Hash       `json:"hash"`
+	Signers           []common.Address  `json:"signers"`
+	Proposals         []*CliqueProposal `json:"proposals"`
+}
+
+func addEpochFlag(cmd *cobra.Command) {
+	cmd.Flags().Uint64("epoch", defaultEpoch, "the clique epoch length to checkpoint and reset the pending votes")
+}
+
+func getEpoch(cmd *cobra.Command) uint64 {
+	epoch, _ := cmd.Flags().GetUint64("epoch")
+	if epoch == 0 {
+		epoch = defaultEpoch
+	}
+	return epoch
+}
+
+// replayCliqueVotes replays the votes from the last checkpoint to the block
+// number and returns the resulting snapshot.
+func replayCliqueVotes(ctx context.Context, client *ethclient.Client, number, epoch uint64, concurrency int) (*cliqueSnapshot, []common.Address, error) {
+	checkpoint := number - number%epoch
+	header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(checkpoint))
+	if err != nil {
+		return nil, nil, fmt.Errorf("get checkpoint header %d error: %v", checkpoint, err)
+	}
+	snap, err := newCliqueSnapshot(header, epoch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("checkpoint block %d: %v", checkpoint, err)
+	}
+	checkpointSigners := snap.signers()
+
+	headers, err := fetchHeaders(ctx, client, checkpoint+1, number, concurrency)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, header := range headers {
+		if header.ParentHash != snap.Hash {
+			return nil, nil, fmt.Errorf("block %d: %v", header.Number.Uint64(), errInvalidVotingChain)
+		}
+		if signer, err := snap.apply(header); err != nil {
+			return nil, nil, fmt.Errorf("block %d sealed by %s: %v", header.Number.Uint64(), signer.String(), err)
+		}
+	}
+
+	return snap, checkpointSigners, nil
+}
+
+// proposals returns the live proposals ordered by votes.
+func (s *cliqueSnapshot) proposals() []*CliqueProposal {
+	proposals := make([]*CliqueProposal, 0, len(s.Tally))
+	for address, tally := range s.Tally {
+		proposal := &CliqueProposal{
+			Address:   address,
+			Authorize: tally.Authorize,
+			Votes:     tally.Votes,
+			Threshold: s.threshold(),
+		}
+		for _, vote := range s.Votes {
+			if vote.Address == address {
+				proposal.Voters = append(proposal.Voters, vote)
+			}
+		}
+		proposals = append(proposals, proposal)
+	}
+	sort.Slice(proposals, func(i, j int) bool {
+		if proposals[i].Votes != proposals[j].Votes {
+			return proposals[i].Votes > proposals[j].Votes
+		}
+		return proposals[i].Address.Hex() < proposals[j].Address.Hex()
+	})
+	return proposals
+}
+
+func (cli *CLI) buildBlockVotesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "votes [blockNumber|latest] [--epoch 30000] [--json]",
+		Short: "Replay the clique votes from the last checkpoint and show the proposals",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx := context.Background()
+
+			var number uint64
+			if len(args) > 0 && args[0] != "latest" {
+				n, err := strconv.ParseUint(args[0], 10, 64)
+				if err != nil {
+					fmt.Println("Number error")
+					return
+				}
+				number = n
+			} else {
+				latest, err := cli.client.BlockNumber(ctx)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				number = latest
+			}
+
+			snap, checkpointSigners, err := replayCliqueVotes(ctx, cli.client, number, getEpoch(cmd), getConcurrency(cmd))
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			votes := &CliqueVotes{
+				Checkpoint:        number - number%snap.Epoch,
+				CheckpointSigners: checkpointSigners,
+				Number:            snap.Number,
+				Hash:              snap.Hash,
+				Signers:           snap.signers(),
+				Proposals:         snap.proposals(),
+			}
+
+			if jsonMode, _ := cmd.Flags().GetBool("json"); jsonMode {
+				b, err := json.MarshalIndent(votes, "", "  ")
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				fmt.Println(string(b))
+				return
+			}
+			showCliqueVotes(votes)
+		},
+	}
+
+	addEpochFlag(cmd)
+	cmd.Flags().Int("concurrency", defaultConcurrency, "the number of concurrent requests")
+	cmd.Flags().Bool("json", false, "show the votes in json")
+
+	return cmd
+}
+
+func showCliqueVotes(votes *CliqueVotes) {
+	fmt.Printf("Checkpoint block %d with %d signers\n", votes.Checkpoint, len(votes.CheckpointSigners))
+	fmt.Printf("Signers at block %d (%s):\n", votes.Number, votes.Hash.String())
+	for _, signer := range votes.Signers {
+		fmt.Println("  ", signer.String())
+	}
+
+	if len(votes.Proposals) == 0 {
+		fmt.Println("No proposal")
+		return
+	}
+	fmt.Println("Proposals:")
+	for _, proposal := range votes.Proposals {
+		action := "Drop"
+		if proposal.Authorize {
+			action = "Auth"
+		}
+		fmt.Printf("  %s %s, %d/%d votes\n", action, proposal.Address.String(), proposal.Votes, proposal.Threshold)
+		for _, vote := range proposal.Voters {
+			fmt.Printf("    block %d by %s\n", vote.Block, vote.Signer.String())
+		}
+	}
+}
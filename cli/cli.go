@@ -14,6 +14,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/spf13/cobra"
 )
 
@@ -35,10 +36,12 @@ type CLI struct {
 	rpcURL     string
 	config     string
 	testing    bool
+	console    bool // run the commands in the console session
 
-	client *ethclient.Client
-	tran   *Transaction
-	wallet *keystore.KeyStore
+	client    *ethclient.Client
+	rpcClient *rpc.Client
+	tran      *Transaction
+	wallet    *keystore.KeyStore
 
 	blockchain BlockChain
 }
@@ -104,20 +107,27 @@ func NewCLI() *CLI {
 		rpcURL:     "",
 		testing:    false,
 		config:     "",
-		tran:       new(Transaction),
+		tran:       newDefaultTransaction(),
 		wallet:     nil,
 		blockchain: bc,
 	}
-	cli.tran.Unit = UnitETH
-	cli.tran.Value = new(big.Int)
-	cli.tran.GasPrice = big.NewInt(1)
-	cli.tran.NetworkID = DefaultChainID
-	cli.tran.GasLimit = 21000
 
 	cli.buildRootCmd()
 	return cli
 }
 
+// newDefaultTransaction returns the transaction with the default unit, gas
+// price, network ID and gas limit
+func newDefaultTransaction() *Transaction {
+	return &Transaction{
+		Unit:      UnitETH,
+		Value:     new(big.Int),
+		GasPrice:  big.NewInt(1),
+		NetworkID: DefaultChainID,
+		GasLimit:  21000,
+	}
+}
+
 // CopyCLI returns an copy  CLI
 func CopyCLI(cli *CLI) *CLI {
 	cpy := &CLI{
@@ -149,6 +159,7 @@ func (cli *CLI) resetConfig() error {
 	if cli.client != nil {
 		cli.client.Close()
 		cli.client = nil
+		cli.rpcClient = nil
 	}
 
 	return nil
@@ -158,10 +169,11 @@ func (cli *CLI) resetConfig() error {
 func (cli *CLI) BuildClient() error {
 	var err error
 	if cli.client == nil {
-		cli.client, err = ethclient.Dial(cli.rpcURL)
+		cli.rpcClient, err = rpc.Dial(cli.rpcURL)
 		if err != nil {
 			return fmt.Errorf("Failed to connect to the %s node: %v", cli.blockchain.String(), err)
 		}
+		cli.client = ethclient.NewClient(cli.rpcClient)
 	}
 	return nil
 }
@@ -174,19 +186,32 @@ func (cli *CLI) Execute() {
 // setup turns up the CLI environment, and gets called by Cobra before
 // a command is executed.
 func (cli *CLI) setup(cmd *cobra.Command, args []string) {
+	if cli.console {
+		cli.setupConsoleCommand(cmd)
+		return
+	}
 	err := cli.setupConfig()
 	if err != nil {
 		fmt.Println(err)
 		fmt.Fprint(os.Stderr, cmd.UsageString())
-		os.Exit(1)
+		cli.exit(1)
 	}
 }
 
 func (cli *CLI) help(cmd *cobra.Command, args []string) {
 	fmt.Fprint(os.Stderr, cmd.UsageString())
 
-	os.Exit(-1)
+	cli.exit(-1)
+
+}
 
+// exit exits with the code, except in the tests and the console session
+// which keep running after the command.
+func (cli *CLI) exit(code int) {
+	if cli.testing || cli.console {
+		return
+	}
+	os.Exit(code)
 }
 
 // TestCommand test command
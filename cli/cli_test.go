@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/newtonproject/newcommander/internal/rpctest"
+	"github.com/spf13/viper"
+)
+
+// The account of the test wallet, which signs the tx of the tx.json fixture
+const (
+	testKey      = "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"
+	testPassword = "newcommander"
+)
+
+// testWallet returns the wallet path with the test account, and sets the
+// password of the account in the config.
+func testWallet(t *testing.T) string {
+	t.Helper()
+	key, err := crypto.HexToECDSA(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletPath := t.TempDir()
+	ks := keystore.NewKeyStore(walletPath, keystore.LightScryptN, keystore.LightScryptP)
+	if _, err := ks.ImportECDSA(key, testPassword); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("pay.password", testPassword)
+	t.Cleanup(viper.Reset)
+	return walletPath
+}
+
+// testGolden runs the commands against the recorded calls of the fixture in
+// testdata, none if the fixture is empty, and compares the outputs with the
+// golden file in testdata.
+func testGolden(t *testing.T, fixture, golden string, commands ...string) {
+	t.Helper()
+	testGoldenWallet(t, fixture, golden, t.TempDir(), commands...)
+}
+
+// testGoldenWallet is testGolden with the wallet path.
+func testGoldenWallet(t *testing.T, fixture, golden, walletPath string, commands ...string) {
+	t.Helper()
+	s := rpctest.NewServer(t, nil)
+	if fixture != "" {
+		s = rpctest.NewServerFile(t, filepath.Join("testdata", fixture))
+	}
+
+	cli := NewCLI()
+	var out bytes.Buffer
+	for _, command := range commands {
+		fmt.Fprintf(&out, "$ newcommander %s\n", command)
+		out.WriteString(cli.TestCommand(fmt.Sprintf("%s -i %s -w %s", command, s.URL(), walletPath)))
+	}
+	rpctest.Golden(t, filepath.Join("testdata", golden), out.Bytes())
+}
+
+// captureStdout returns the output of fn printed to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		var b bytes.Buffer
+		io.Copy(&b, r)
+		out <- b.String()
+	}()
+	fn()
+	w.Close()
+	return <-out
+}
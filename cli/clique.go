@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// defaultEpoch is the default number of blocks after which to checkpoint
+// and reset the pending votes
+const defaultEpoch = 30000
+
+var (
+	nonceAuthVote = hexutil.MustDecode("0xffffffffffffffff") // Magic nonce number to vote on adding a new signer
+	nonceDropVote = hexutil.MustDecode("0x0000000000000000") // Magic nonce number to vote on removing a signer.
+)
+
+var (
+	errMissingVanity        = errors.New("extra-data 32 byte vanity prefix missing")
+	errMissingSignature     = errors.New("extra-data 65 byte signature suffix missing")
+	errInvalidCheckpoint    = errors.New("invalid signer list on checkpoint block")
+	errInvalidVotingChain   = errors.New("invalid voting chain")
+	errInvalidVote          = errors.New("vote nonce not 0x00..0 or 0xff..f")
+	errUnauthorizedSigner   = errors.New("unauthorized signer")
+	errRecentlySigned       = errors.New("recently signed")
+	errMismatchingSignerSet = errors.New("mismatching signer list on checkpoint block")
+)
+
+// cliqueVote is a single vote that an authorized signer made to modify the
+// list of authorizations.
+type cliqueVote struct {
+	Signer    common.Address `json:"signer"`
+	Block     uint64         `json:"block"`
+	Address   common.Address `json:"address"`
+	Authorize bool           `json:"authorize"`
+}
+
+// cliqueTally is a simple vote tally to keep the current score of votes.
+type cliqueTally struct {
+	Authorize bool `json:"authorize"`
+	Votes     int  `json:"votes"`
+}
+
+// cliqueSnapshot is the state of the clique authorization voting at a given
+// block, it is replayed from a checkpoint the same way clique does.
+type cliqueSnapshot struct {
+	Epoch   uint64
+	Number  uint64
+	Hash    common.Hash
+	Signers map[common.Address]struct{}
+	Recents map[uint64]common.Address
+	Votes   []*cliqueVote
+	Tally   map[common.Address]cliqueTally
+}
+
+// checkExtra checks the vanity and seal of the header extra-data, and the
+// signer list on a checkpoint block.
+func checkExtra(header *types.Header, epoch uint64) error {
+	if len(header.Extra) < extraVanity {
+		return errMissingVanity
+	}
+	if len(header.Extra) < extraVanity+extraSeal {
+		return errMissingSignature
+	}
+	signersBytes := len(header.Extra) - extraVanity - extraSeal
+	checkpoint := epoch > 0 && header.Number.Uint64()%epoch == 0
+	if !checkpoint && signersBytes != 0 {
+		return errors.New("non-checkpoint block contains extra signer list")
+	}
+	if checkpoint && signersBytes%common.AddressLength != 0 {
+		return errInvalidCheckpoint
+	}
+	return nil
+}
+
+// checkpointSigners decodes the signer list from the extra-data of a
+// checkpoint header.
+func checkpointSigners(header *types.Header) ([]common.Address, error) {
+	if len(header.Extra) < extraVanity+extraSeal {
+		return nil, errMissingSignature
+	}
+	signersBytes := header.Extra[extraVanity : len(header.Extra)-extraSeal]
+	if len(signersBytes) == 0 || len(signersBytes)%common.AddressLength != 0 {
+		return nil, errInvalidCheckpoint
+	}
+	signers := make([]common.Address, len(signersBytes)/common.AddressLength)
+	for i := range signers {
+		copy(signers[i][:], signersBytes[i*common.AddressLength:])
+	}
+	return signers, nil
+}
+
+// newCliqueSnapshot creates the snapshot at the checkpoint header.
+func newCliqueSnapshot(checkpoint *types.Header, epoch uint64) (*cliqueSnapshot, error) {
+	if epoch == 0 {
+		epoch = defaultEpoch
+	}
+	if checkpoint.Number.Uint64()%epoch != 0 {
+		return nil, fmt.Errorf("block %d is not a checkpoint of epoch %d", checkpoint.Number.Uint64(), epoch)
+	}
+	signers, err := checkpointSigners(checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	snap := &cliqueSnapshot{
+		Epoch:   epoch,
+		Number:  checkpoint.Number.Uint64(),
+		Hash:    checkpoint.Hash(),
+		Signers: make(map[common.Address]struct{}),
+		Recents: make(map[uint64]common.Address),
+		Tally:   make(map[common.Address]cliqueTally),
+	}
+	for _, signer := range signers {
+		snap.Signers[signer] = struct{}{}
+	}
+	return snap, nil
+}
+
+// signers retrieves the list of authorized signers in ascending order.
+func (s *cliqueSnapshot) signers() []common.Address {
+	signers := make([]common.Address, 0, len(s.Signers))
+	for signer := range s.Signers {
+		signers = append(signers, signer)
+	}
+	sortAddresses(signers)
+	return signers
+}
+
+// threshold returns the number of votes to pass a proposal.
+func (s *cliqueSnapshot) threshold() int {
+	return len(s.Signers)/2 + 1
+}
+
+// inturn returns if a signer at a given block height is in-turn or not.
+func (s *cliqueSnapshot) inturn(number uint64, signer common.Address) bool {
+	signers := s.signers()
+	if len(signers) == 0 {
+		return false
+	}
+	return signers[number%uint64(len(signers))] == signer
+}
+
+// validVote returns whether it makes sense to cast the specified vote in the
+// given snapshot context (e.g. don't try to add an already authorized signer).
+func (s *cliqueSnapshot) validVote(address common.Address, authorize bool) bool {
+	_, signer := s.Signers[address]
+	return (signer && !authorize) || (!signer && authorize)
+}
+
+// cast adds a new vote into the tally.
+func (s *cliqueSnapshot) cast(address common.Address, authorize bool) bool {
+	if !s.validVote(address, authorize) {
+		return false
+	}
+	if old, ok := s.Tally[address]; ok {
+		old.Votes++
+		s.Tally[address] = old
+	} else {
+		s.Tally[address] = cliqueTally{Authorize: authorize, Votes: 1}
+	}
+	return true
+}
+
+// uncast removes a previously cast vote from the tally.
+func (s *cliqueSnapshot) uncast(address common.Address, authorize bool) bool {
+	tally, ok := s.Tally[address]
+	if !ok {
+		return false
+	}
+	if tally.Authorize != authorize {
+		return false
+	}
+	if tally.Votes > 1 {
+		tally.Votes--
+		s.Tally[address] = tally
+	} else {
+		delete(s.Tally, address)
+	}
+	return true
+}
+
+// apply applies the next header to the snapshot and returns its signer. The
+// snapshot is not changed if the header can not be applied.
+func (s *cliqueSnapshot) apply(header *types.Header) (common.Address, error) {
+	number := header.Number.Uint64()
+	if number != s.Number+1 {
+		return common.Address{}, errInvalidVotingChain
+	}
+	if err := checkExtra(header, s.Epoch); err != nil {
+		return common.Address{}, err
+	}
+	signer, err := ecrecover(header)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if _, ok := s.Signers[signer]; !ok {
+		return signer, errUnauthorizedSigner
+	}
+	limit := uint64(len(s.Signers)/2 + 1)
+	for seen, recent := range s.Recents {
+		if recent == signer && (number < limit || seen > number-limit) {
+			return signer, errRecentlySigned
+		}
+	}
+	var authorize bool
+	switch {
+	case bytes.Equal(header.Nonce[:], nonceAuthVote):
+		authorize = true
+	case bytes.Equal(header.Nonce[:], nonceDropVote):
+		authorize = false
+	default:
+		return signer, errInvalidVote
+	}
+	checkpoint := number%s.Epoch == 0
+	if checkpoint {
+		signers, err := checkpointSigners(header)
+		if err != nil {
+			return signer, err
+		}
+		if !equalAddresses(signers, s.signers()) {
+			return signer, errMismatchingSignerSet
+		}
+		// Remove any votes on checkpoint blocks
+		s.Votes = nil
+		s.Tally = make(map[common.Address]cliqueTally)
+	}
+
+	// Delete the oldest signer from the recent list to allow it signing again
+	if number >= limit {
+		delete(s.Recents, number-limit)
+	}
+	s.Recents[number] = signer
+	s.Number = number
+	s.Hash = header.Hash()
+
+	// Header authorized, discard any previous votes from the signer
+	for i, vote := range s.Votes {
+		if vote.Signer == signer && vote.Address == header.Coinbase {
+			s.uncast(vote.Address, vote.Authorize)
+			s.Votes = append(s.Votes[:i], s.Votes[i+1:]...)
+			break // only one vote allowed
+		}
+	}
+	// Tally up the new vote from the signer
+	if s.cast(header.Coinbase, authorize) {
+		s.Votes = append(s.Votes, &cliqueVote{
+			Signer:    signer,
+			Block:     number,
+			Address:   header.Coinbase,
+			Authorize: authorize,
+		})
+	}
+	// If the vote passed, update the list of signers
+	if tally := s.Tally[header.Coinbase]; tally.Votes > len(s.Signers)/2 {
+		if tally.Authorize {
+			s.Signers[header.Coinbase] = struct{}{}
+		} else {
+			delete(s.Signers, header.Coinbase)
+
+			// Signer list shrunk, delete any leftover recent caches
+			if limit := uint64(len(s.Signers)/2 + 1); number >= limit {
+				delete(s.Recents, number-limit)
+			}
+			// Discard any previous votes the deauthorized signer cast
+			for i := 0; i < len(s.Votes); i++ {
+				if s.Votes[i].Signer == header.Coinbase {
+					s.uncast(s.Votes[i].Address, s.Votes[i].Authorize)
+					s.Votes = append(s.Votes[:i], s.Votes[i+1:]...)
+					i--
+				}
+			}
+		}
+		// Discard any previous votes around the just changed account
+		for i := 0; i < len(s.Votes); i++ {
+			if s.Votes[i].Address == header.Coinbase {
+				s.Votes = append(s.Votes[:i], s.Votes[i+1:]...)
+				i--
+			}
+		}
+		delete(s.Tally, header.Coinbase)
+	}
+
+	return signer, nil
+}
+
+// skip moves the snapshot over a header that can not be applied, so the
+// following headers can still be checked.
+func (s *cliqueSnapshot) skip(header *types.Header) {
+	s.Number = header.Number.Uint64()
+	s.Hash = header.Hash()
+}
+
+func equalAddresses(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+func (cli *CLI) buildCliqueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "clique [signers|proposals|propose|discard]",
+		Short:                 "Manage the clique signers of " + cli.blockchain.String(),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(cli.buildCliqueSignersCmd())
+	cmd.AddCommand(cli.buildCliqueProposalsCmd())
+	cmd.AddCommand(cli.buildCliqueProposeCmd())
+	cmd.AddCommand(cli.buildCliqueDiscardCmd())
+
+	return cmd
+}
+
+// cliqueProposals returns the proposals the node is voting on.
+func (cli *CLI) cliqueProposals(ctx context.Context) (map[common.Address]bool, error) {
+	proposals := make(map[common.Address]bool)
+	if err := cli.rpcClient.CallContext(ctx, &proposals, "clique_proposals"); err != nil {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+func (cli *CLI) buildCliqueSignersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "signers [blockNumber|latest] [--blocks number] [--replay]",
+		Short: "Show the authorized signers and check them against the recent sealed blocks",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx := context.Background()
+
+			var number uint64
+			if len(args) > 0 && args[0] != "latest" {
+				n, err := strconv.ParseUint(args[0], 10, 64)
+				if err != nil {
+					fmt.Println("Number error")
+					return
+				}
+				number = n
+			} else {
+				latest, err := cli.client.BlockNumber(ctx)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				number = latest
+			}
+
+			signers, err := getCliqueSigners(ctx, cli.rpcClient, new(big.Int).SetUint64(number))
+			if err != nil {
+				fmt.Println("Get clique signers error:", err)
+				return
+			}
+
+			// recover the sealers of the recent blocks
+			blocks, _ := cmd.Flags().GetUint64("blocks")
+			if blocks == 0 {
+				blocks = uint64(len(signers)) * 2
+			}
+			from := uint64(1)
+			if number >= blocks {
+				from = number - blocks + 1
+			}
+			headers, err := fetchHeaders(ctx, cli.client, from, number, defaultConcurrency)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			lastSealed := make(map[common.Address]uint64)
+			var unknown []string
+			for _, header := range headers {
+				signer, err := ecrecover(header)
+				if err != nil {
+					fmt.Printf("Block %d: recover signer error: %v\n", header.Number.Uint64(), err)
+					continue
+				}
+				lastSealed[signer] = header.Number.Uint64()
+				if !addressInSlice(signer, signers) {
+					unknown = append(unknown, fmt.Sprintf("block %d sealed by %s which is not in the signer list",
+						header.Number.Uint64(), signer.String()))
+				}
+			}
+
+			fmt.Printf("Signers at block %d:\n", number)
+			for _, signer := range signers {
+				sealed := "not sealed"
+				if n, ok := lastSealed[signer]; ok {
+					sealed = fmt.Sprintf("last sealed block %d", n)
+				}
+				fmt.Printf("  %s %s in the last %d blocks\n", signer.String(), sealed, len(headers))
+			}
+			for _, msg := range unknown {
+				fmt.Println("Warning:", msg)
+			}
+
+			if replay, _ := cmd.Flags().GetBool("replay"); replay {
+				snap, _, err := replayCliqueVotes(ctx, cli.client, number, getEpoch(cmd), defaultConcurrency)
+				if err != nil {
+					fmt.Println("Replay clique votes error:", err)
+					return
+				}
+				if local := snap.signers(); !equalAddresses(local, signers) {
+					fmt.Println("Warning: the signers replayed from the headers mismatch the node:")
+					for _, signer := range local {
+						fmt.Println("  ", signer.String())
+					}
+					return
+				}
+				fmt.Println("The signers match the votes replayed from the headers")
+			}
+		},
+	}
+
+	cmd.Flags().Uint64("blocks", 0, "the number of recent blocks to check the sealers, default twice the number of signers")
+	cmd.Flags().Bool("replay", false, "replay the votes from the last checkpoint to check the signers")
+	addEpochFlag(cmd)
+
+	return cmd
+}
+
+func (cli *CLI) buildCliqueProposalsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proposals",
+		Short: "Show the proposals the node is voting on",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx := context.Background()
+
+			proposals, err := cli.cliqueProposals(ctx)
+			if err != nil {
+				fmt.Println("Get clique proposals error:", err)
+				return
+			}
+			if len(proposals) == 0 {
+				fmt.Println("No proposal")
+				return
+			}
+			signers, err := getCliqueSigners(ctx, cli.rpcClient, nil)
+			if err != nil {
+				fmt.Println("Get clique signers error:", err)
+			}
+
+			addresses := make([]common.Address, 0, len(proposals))
+			for address := range proposals {
+				addresses = append(addresses, address)
+			}
+			sortAddresses(addresses)
+			for _, address := range addresses {
+				fmt.Println(proposalString(address, proposals[address], signers))
+			}
+		},
+	}
+
+	return cmd
+}
+
+// proposalString shows the proposal and if it takes no effect with the
+// current signers.
+func proposalString(address common.Address, authorize bool, signers []common.Address) string {
+	action := "Drop"
+	if authorize {
+		action = "Auth"
+	}
+	s := fmt.Sprintf("%s %s", action, address.String())
+	if signers == nil {
+		return s
+	}
+	if isSigner := addressInSlice(address, signers); isSigner == authorize {
+		if isSigner {
+			s += " (no effect, already a signer)"
+		} else {
+			s += " (no effect, not a signer)"
+		}
+	}
+	return s
+}
+
+func (cli *CLI) buildCliqueProposeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "propose <address> <auth|drop>",
+		Short: "Propose to authorize or drop a signer, the node votes on it when sealing",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			address, err := cli.toAddress(args[0])
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			var authorize bool
+			switch args[1] {
+			case "auth":
+				authorize = true
+			case "drop":
+				authorize = false
+			default:
+				fmt.Println("Error: the vote must be auth or drop")
+				return
+			}
+
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx := context.Background()
+
+			signers, err := getCliqueSigners(ctx, cli.rpcClient, nil)
+			if err != nil {
+				fmt.Println("Get clique signers error:", err)
+				return
+			}
+			if addressInSlice(address, signers) == authorize {
+				fmt.Println("Warning:", proposalString(address, authorize, signers))
+			}
+
+			if err := cli.rpcClient.CallContext(ctx, nil, "clique_propose", address, authorize); err != nil {
+				fmt.Println("Propose error:", err)
+				return
+			}
+			proposals, err := cli.cliqueProposals(ctx)
+			if err != nil {
+				fmt.Println("Get clique proposals error:", err)
+				return
+			}
+			if auth, ok := proposals[address]; !ok || auth != authorize {
+				fmt.Println("Error: the proposal is not found on the node")
+				return
+			}
+			fmt.Printf("Succeed proposed: %s\n", proposalString(address, authorize, nil))
+		},
+	}
+
+	return cmd
+}
+
+func (cli *CLI) buildCliqueDiscardCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "discard <address>",
+		Short: "Discard the proposal of the address",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			address, err := cli.toAddress(args[0])
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx := context.Background()
+
+			if err := cli.rpcClient.CallContext(ctx, nil, "clique_discard", address); err != nil {
+				fmt.Println("Discard error:", err)
+				return
+			}
+			proposals, err := cli.cliqueProposals(ctx)
+			if err != nil {
+				fmt.Println("Get clique proposals error:", err)
+				return
+			}
+			if _, ok := proposals[address]; ok {
+				fmt.Println("Error: the proposal is still on the node")
+				return
+			}
+			fmt.Printf("Succeed discarded the proposal of %s\n", address.String())
+		},
+	}
+
+	return cmd
+}
+
+func addressInSlice(address common.Address, list []common.Address) bool {
+	for _, a := range list {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestClique(t *testing.T) {
+	testGolden(t, "clique.json", "clique.golden",
+		"clique signers",
+		"clique signers 4 --blocks 2",
+		"clique signers --replay",
+		"clique proposals",
+	)
+}
+
+func TestProposalString(t *testing.T) {
+	signer := common.HexToAddress("0x97549e368acafdcae786bb93d98379f1d1561a29")
+	other := common.HexToAddress("0x82a3a88bc9d6a70c4f3c66534566892eae0cad81")
+	signers := []common.Address{signer}
+
+	for _, tt := range []struct {
+		address   common.Address
+		authorize bool
+		noEffect  bool
+	}{
+		{signer, true, true},
+		{signer, false, false},
+		{other, true, false},
+		{other, false, true},
+	} {
+		s := proposalString(tt.address, tt.authorize, signers)
+		if strings.Contains(s, "no effect") != tt.noEffect {
+			t.Errorf("proposalString(%s, %v) = %s", tt.address.String(), tt.authorize, s)
+		}
+	}
+}
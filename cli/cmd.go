@@ -44,6 +44,7 @@ func (cli *CLI) buildRootCmd() {
 	rootCmd.AddCommand(cli.buildBuildCmd())     // build tx
 	rootCmd.AddCommand(cli.buildSignCmd())      // sign tx
 	rootCmd.AddCommand(cli.buildBroadcastCmd()) // submit/broadcast
+	rootCmd.AddCommand(cli.buildTxCmd())        // tx show
 
 	// rpc
 	rootCmd.AddCommand(cli.buildRPCCmd()) // rpc
@@ -52,7 +53,17 @@ func (cli *CLI) buildRootCmd() {
 	rootCmd.AddCommand(cli.buildBatchPayCmd()) // batch
 
 	// tools
-	rootCmd.AddCommand(cli.buildDecodeCmd()) // decode
-	rootCmd.AddCommand(cli.buildBlockCmd())  // block
-	rootCmd.AddCommand(cli.buildTraceCmd())  // trace
+	rootCmd.AddCommand(cli.buildDecodeCmd())       // decode
+	rootCmd.AddCommand(cli.buildEncodeCmd())       // encode
+	rootCmd.AddCommand(cli.buildABICmd())          // abi
+	rootCmd.AddCommand(cli.buildBlockCmd())        // block
+	rootCmd.AddCommand(cli.buildCliqueCmd())       // clique
+	rootCmd.AddCommand(cli.buildMonitorCmd())      // monitor
+	rootCmd.AddCommand(cli.buildServeMetricsCmd()) // serve-metrics
+	rootCmd.AddCommand(cli.buildHistoryCmd())      // history
+	rootCmd.AddCommand(cli.buildIndexCmd())        // index
+	rootCmd.AddCommand(cli.buildTraceCmd())        // trace
+
+	// console
+	rootCmd.AddCommand(cli.buildConsoleCmd()) // console
 }
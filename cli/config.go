@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
@@ -109,3 +111,18 @@ func (cli *CLI) setDefaultTransaction() error {
 
 	return nil
 }
+
+// setFlagsFromConfig sets the flags not changed on the command line to the
+// keys set in the config file. The flags are not bound to viper, so init
+// does not write their defaults to the config file.
+func setFlagsFromConfig(cmd *cobra.Command, keys map[string]string) error {
+	for name, key := range keys {
+		if cmd.Flags().Changed(name) || !viper.IsSet(key) {
+			continue
+		}
+		if err := cmd.Flags().Set(name, viper.GetString(key)); err != nil {
+			return fmt.Errorf("invalid config %s: %v", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,352 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	prompt2 "github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/peterh/liner"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultConsoleHistoryFile = ".newcommander_history"
+	consoleHistoryLimit       = 1000
+)
+
+// secretConsoleLine matches the lines not kept in the history, the personal
+// RPC methods take the account passwords and keys.
+var secretConsoleLine = regexp.MustCompile(`\bpersonal_`)
+
+// consolePrompter reads the lines of the console
+var consolePrompter prompt2.UserPrompter = prompt2.Stdin
+
+// The builtin commands of the console
+const (
+	consoleExit = "exit"
+	consoleQuit = "quit"
+	consoleLock = "lock"
+)
+
+func (cli *CLI) buildConsoleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "console [--history file]",
+		Short: "Run the commands in an interactive console",
+		Long: `Run the commands in an interactive console, which reads the config once and keeps
+the RPC client and the unlocked accounts for the whole session.
+Type the commands without the program name, 'lock' to lock the unlocked accounts, 'exit' or Ctrl-D to quit.
+The lines calling the personal RPC methods are not kept in the history, they carry the passwords and keys.`,
+		Args:                  cobra.NoArgs,
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			history, _ := cmd.Flags().GetString("history")
+			if err := cli.runConsole(consolePrompter, history); err != nil {
+				fmt.Println(err)
+				return
+			}
+		},
+	}
+
+	history := ""
+	if home, err := os.UserHomeDir(); err == nil {
+		history = filepath.Join(home, defaultConsoleHistoryFile)
+	}
+	cmd.Flags().String("history", history, "the history `file` of the console, empty for no history")
+
+	return cmd
+}
+
+// runConsole reads and runs the commands until exit, the commands share the
+// CLI and the config read before the console.
+func (cli *CLI) runConsole(p prompt2.UserPrompter, historyFile string) error {
+	var history *os.File
+	if historyFile != "" {
+		lines, err := readConsoleHistory(historyFile)
+		if err != nil {
+			return err
+		}
+		p.SetHistory(lines)
+
+		history, err = os.OpenFile(historyFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer history.Close()
+	}
+	p.SetWordCompleter(cli.completeConsole)
+
+	cli.console = true
+	defer func() {
+		cli.console = false
+		cli.lockWallet()
+	}()
+
+	fmt.Printf("Welcome to the %s console, %s, RPC %s, wallet %s\n", cli.Name, cli.version, cli.rpcURL, cli.walletPath)
+	fmt.Println("Type the commands without the program name, 'lock' to lock the unlocked accounts, 'exit' to quit")
+	for {
+		line, err := p.PromptInput("> ")
+		if err == liner.ErrPromptAborted {
+			continue
+		} else if err == io.EOF {
+			fmt.Println()
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !secretConsoleLine.MatchString(line) {
+			p.AppendHistory(line)
+			if history != nil {
+				fmt.Fprintln(history, line)
+			}
+		}
+
+		args, err := splitConsoleArgs(line)
+		if err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+		switch args[0] {
+		case consoleExit, consoleQuit:
+			return nil
+		case consoleLock:
+			cli.lockWallet()
+			fmt.Println("Locked the accounts of the session")
+			continue
+		case cli.Name:
+			// the commands pasted with the program name
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] == "console" {
+			fmt.Println("Error: already in the console")
+			continue
+		}
+		if cmd, _, err := cli.rootCmd.Find(args); err == nil && cmd == cli.rootCmd {
+			// the root command exits with the usage
+			fmt.Print(cli.rootCmd.UsageString())
+			continue
+		}
+
+		cli.rootCmd.SetArgs(args)
+		cli.rootCmd.Execute()
+		cli.buildRootCmd()
+	}
+}
+
+// setupConsoleCommand prepares the command of the console session without
+// reading the config again, the changed global flags apply to the rest of
+// the session.
+func (cli *CLI) setupConsoleCommand(cmd *cobra.Command) {
+	cli.tran = newDefaultTransaction()
+	cli.setDefaultTransaction()
+
+	flags := cmd.Flags()
+	if flags.Changed("rpcURL") {
+		if rpcURL, _ := flags.GetString("rpcURL"); rpcURL != cli.rpcURL {
+			if cli.client != nil {
+				cli.client.Close()
+				cli.client = nil
+				cli.rpcClient = nil
+			}
+			cli.rpcURL = rpcURL
+		}
+	}
+	if flags.Changed("walletPath") {
+		if walletPath, _ := flags.GetString("walletPath"); walletPath != cli.walletPath {
+			cli.lockWallet()
+			cli.wallet = nil
+			cli.walletPath = walletPath
+		}
+	}
+}
+
+// accountUnlocked reports whether the account is unlocked in the wallet,
+// which keeps the accounts unlocked in the console session.
+func (cli *CLI) accountUnlocked(account accounts.Account) bool {
+	_, err := cli.wallet.SignHash(account, make([]byte, 32))
+	return err == nil
+}
+
+// lockWallet locks all the accounts of the wallet.
+func (cli *CLI) lockWallet() {
+	if cli.wallet == nil {
+		return
+	}
+	for _, account := range cli.wallet.Accounts() {
+		cli.wallet.Lock(account.Address)
+	}
+}
+
+// completeConsole completes the word before the cursor with the commands,
+// the flags of the command and the addresses of the wallet.
+func (cli *CLI) completeConsole(line string, pos int) (string, []string, string) {
+	if pos > len(line) {
+		pos = len(line)
+	}
+	start := strings.LastIndexAny(line[:pos], " \t") + 1
+	head, word, tail := line[:start], line[start:pos], line[pos:]
+
+	var candidates []string
+	words := strings.Fields(head)
+	if len(words) > 0 && words[0] == cli.Name {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		candidates = append(commandNames(cli.rootCmd), consoleExit, consoleQuit, consoleLock)
+	} else {
+		cmd, _, err := cli.rootCmd.Find(words)
+		if err != nil {
+			return head, nil, tail
+		}
+		switch {
+		case strings.HasPrefix(word, "-"):
+			candidates = flagNames(cmd)
+		case cmd.HasAvailableSubCommands() && len(word) == 0:
+			candidates = commandNames(cmd)
+		default:
+			candidates = append(commandNames(cmd), cli.walletAddresses()...)
+		}
+	}
+
+	var completions []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(word)) {
+			completions = append(completions, candidate)
+		}
+	}
+	return head, completions, tail
+}
+
+// walletAddresses returns the addresses of the wallet
+func (cli *CLI) walletAddresses() []string {
+	if cli.wallet == nil {
+		if _, err := os.Stat(cli.walletPath); err != nil {
+			return nil
+		}
+		cli.wallet = keystore.NewKeyStore(cli.walletPath,
+			keystore.LightScryptN, keystore.LightScryptP)
+	}
+
+	var addresses []string
+	for _, account := range cli.wallet.Accounts() {
+		addresses = append(addresses, account.Address.String())
+	}
+	return addresses
+}
+
+func commandNames(cmd *cobra.Command) []string {
+	var names []string
+	for _, c := range cmd.Commands() {
+		if c.IsAvailableCommand() || c.Name() == "help" {
+			names = append(names, c.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func flagNames(cmd *cobra.Command) []string {
+	var names []string
+	add := func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		names = append(names, "--"+f.Name)
+		if f.Shorthand != "" {
+			names = append(names, "-"+f.Shorthand)
+		}
+	}
+	cmd.LocalFlags().VisitAll(add)
+	cmd.InheritedFlags().VisitAll(add)
+	sort.Strings(names)
+	return names
+}
+
+// splitConsoleArgs splits the line into the args like the shell, with the
+// single and double quotes and the backslash escapes.
+func splitConsoleArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		arg     strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote %c", quote)
+	}
+	if escaped {
+		return nil, errors.New("unterminated escape")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// readConsoleHistory reads the last lines of the history file, none if the
+// file does not exist.
+func readConsoleHistory(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > consoleHistoryLimit {
+		lines = lines[len(lines)-consoleHistoryLimit:]
+	}
+	return lines, scanner.Err()
+}
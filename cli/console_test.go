@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	prompt2 "github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/newtonproject/newcommander/internal/rpctest"
+)
+
+// testPrompter is the prompter of the scripted console input
+type testPrompter struct {
+	lines     []string
+	history   []string
+	completer prompt2.WordCompleter
+}
+
+func (p *testPrompter) PromptInput(prompt string) (string, error) {
+	if len(p.lines) == 0 {
+		return "", io.EOF
+	}
+	line := p.lines[0]
+	p.lines = p.lines[1:]
+	return line, nil
+}
+
+func (p *testPrompter) PromptPassword(prompt string) (string, error) { return "", nil }
+func (p *testPrompter) PromptConfirm(prompt string) (bool, error)    { return false, nil }
+func (p *testPrompter) SetHistory(history []string)                  { p.history = history }
+func (p *testPrompter) AppendHistory(command string)                 { p.history = append(p.history, command) }
+func (p *testPrompter) ClearHistory()                                { p.history = nil }
+func (p *testPrompter) SetWordCompleter(completer prompt2.WordCompleter) {
+	p.completer = completer
+}
+
+func TestConsole(t *testing.T) {
+	s := rpctest.NewServerFile(t, filepath.Join("testdata", "balance.json"))
+	history := filepath.Join(t.TempDir(), "history")
+	if err := ioutil.WriteFile(history, []byte("version\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cli := NewCLI()
+	p := &testPrompter{lines: []string{
+		"balance 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 -u ISAAC",
+		"",
+		cli.Name + " balance 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481",
+		"console",
+		"-w /tmp",
+		"rpc 'eth_chainId",
+		"rpc personal_unlockAccount 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 str:secret dec:300",
+		cli.Name + " rpc personal_importRawKey " + testKey + " str:secret",
+		"exit",
+		"balance 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481",
+	}}
+	defer func(prompter prompt2.UserPrompter) { consolePrompter = prompter }(consolePrompter)
+	consolePrompter = p
+
+	out := cli.TestCommand(fmt.Sprintf("console --history %s -i %s -w %s", history, s.URL(), t.TempDir()))
+
+	for _, want := range []string{
+		"Address[0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481] Balance[500000000000000000000 ISAAC]\n",
+		"Address[0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481] Balance[500 NEW]\n",
+		"Error: already in the console\nUsage:\n",
+		"Error: unterminated quote '\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "Number Of Accounts"); n != 2 {
+		t.Errorf("got %d balance outputs, want 2 before exit:\n%s", n, out)
+	}
+	if len(p.lines) != 1 {
+		t.Errorf("got %d lines left, want the line after exit", len(p.lines))
+	}
+	if cli.console {
+		t.Error("the console mode is on after exit")
+	}
+
+	b, err := ioutil.ReadFile(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "version\nbalance 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 -u ISAAC\n" + cli.Name + " balance 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481\nconsole\n-w /tmp\nrpc 'eth_chainId\nexit\n"
+	if string(b) != want {
+		t.Errorf("history %q, want %q", b, want)
+	}
+	// the personal methods with the passwords and keys are not kept
+	if len(p.history) != 7 || p.history[0] != "version" {
+		t.Errorf("unexpected prompter history %q", p.history)
+	}
+	if p.completer == nil {
+		t.Error("the word completer is not set")
+	}
+}
+
+func TestSplitConsoleArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"balance  0x01\t-u ISAAC", []string{"balance", "0x01", "-u", "ISAAC"}},
+		{`pay 1 --to 0x01 --data "hello world"`, []string{"pay", "1", "--to", "0x01", "--data", "hello world"}},
+		{`rpc eth_call '{"to": "0x01"}' latest`, []string{"rpc", "eth_call", `{"to": "0x01"}`, "latest"}},
+		{`rpc a\ b "c\"d" ''`, []string{"rpc", "a b", `c"d`, ""}},
+	}
+	for _, test := range tests {
+		got, err := splitConsoleArgs(test.line)
+		if err != nil {
+			t.Errorf("%s: %v", test.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.line, got, test.want)
+		}
+	}
+
+	for _, line := range []string{`rpc "eth_call`, `rpc 'a`, `rpc a\`} {
+		if _, err := splitConsoleArgs(line); err == nil {
+			t.Errorf("%s: want error", line)
+		}
+	}
+}
+
+func TestCompleteConsole(t *testing.T) {
+	cli := NewCLI()
+	cli.walletPath = t.TempDir()
+
+	tests := []struct {
+		line, head string
+		want       []string
+	}{
+		{"ba", "", []string{"balance", "batchpay"}},
+		{cli.Name + " tr", cli.Name + " ", []string{"trace"}},
+		{"rpc ", "rpc ", []string{"batch"}},
+		{"trace st", "trace ", []string{"state"}},
+		{"rpc batch --si", "rpc batch ", []string{"--size"}},
+		{"pay 1 --wallet", "pay 1 ", []string{"--walletPath"}},
+		{"nothing ", "nothing ", nil},
+	}
+	for _, test := range tests {
+		head, got, tail := cli.completeConsole(test.line, len(test.line))
+		if head != test.head || !reflect.DeepEqual(got, test.want) || tail != "" {
+			t.Errorf("%q: got %q %q %q, want %q %q", test.line, head, got, tail, test.head, test.want)
+		}
+	}
+}
+
+func TestConsoleExit(t *testing.T) {
+	s := rpctest.NewServer(t, nil)
+
+	// the commands exiting with a status run outside of the tests
+	cli := NewCLI()
+	cli.rpcURL = s.URL()
+	p := &testPrompter{lines: []string{"monitor", "version"}}
+	out := captureStdout(t, func() {
+		if err := cli.runConsole(p, ""); err != nil {
+			t.Error(err)
+		}
+	})
+
+	for _, want := range []string{"CRITICAL - rpc error:", cli.version} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package cli
 
 import (
+	"bufio"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"os"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -16,241 +20,428 @@ import (
 )
 
 type TxData struct {
-	From  common.Address  `json:"from"`
+	Type  uint8           `json:"type"`
+	From  *common.Address `json:"from,omitempty"`
 	To    *common.Address `json:"to"`
 	Value string          `json:"value"`
 	Data  string          `json:"data"`
 
-	Nonce    uint64   `json:"nonce"    gencodec:"required"`
-	Price    *big.Int `json:"gasPrice" gencodec:"required"`
-	GasLimit uint64   `json:"gas"      gencodec:"required"`
+	Nonce      uint64           `json:"nonce"    gencodec:"required"`
+	Price      *big.Int         `json:"gasPrice" gencodec:"required"`
+	GasTipCap  *big.Int         `json:"maxPriorityFeePerGas,omitempty"`
+	GasFeeCap  *big.Int         `json:"maxFeePerGas,omitempty"`
+	GasLimit   uint64           `json:"gas"      gencodec:"required"`
+	MaxFee     string           `json:"maxTotalFee"`
+	AccessList types.AccessList `json:"accessList,omitempty"`
+
+	// NewChain addresses
+	NewFrom string `json:"newFrom,omitempty"`
+	NewTo   string `json:"newTo,omitempty"`
 
 	// Signature values
-	V string `json:"v" gencodec:"required"`
-	R string `json:"r" gencodec:"required"`
-	S string `json:"s" gencodec:"required"`
+	V string `json:"v,omitempty"`
+	R string `json:"r,omitempty"`
+	S string `json:"s,omitempty"`
 
 	// This is only used when marshaling to JSON.
-	Hash common.Hash `json:"hash" rlp:"-"`
+	Hash *common.Hash `json:"hash,omitempty" rlp:"-"`
 
 	ChainID *big.Int `json:"chainID"`
 
+	Signed            bool   `json:"signed"`
 	Raw               string `json:"raw" rlp:"-"`
 	UnsignedRawTx     string `json:"UnsignedRawTx" rlp:"-"`
 	UnsignedRawTxHash string `json:"UnsignedRawTxHash" rlp:"-"`
-	PublicKey         string `json:"PublicKey" rlp:"-"`
+	PublicKey         string `json:"PublicKey,omitempty" rlp:"-"`
+
+	// Call is the decoded data if the method is known
+	Call *DecodedCall `json:"call,omitempty" rlp:"-"`
+}
+
+// unsignedLegacyTx is the homestead or EIP-155 signing payload of a legacy tx
+type unsignedLegacyTx struct {
+	Nonce    uint64
+	GasPrice *big.Int
+	Gas      uint64
+	To       *common.Address `rlp:"nil"`
+	Value    *big.Int
+	Data     []byte
+	ChainID  *big.Int `rlp:"optional"`
+	Zero1    uint     `rlp:"optional"`
+	Zero2    uint     `rlp:"optional"`
+}
+
+// unsignedAccessListTx is the EIP-2930 signing payload without type prefix
+type unsignedAccessListTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasPrice   *big.Int
+	Gas        uint64
+	To         *common.Address `rlp:"nil"`
+	Value      *big.Int
+	Data       []byte
+	AccessList types.AccessList
+}
+
+// unsignedDynamicFeeTx is the EIP-1559 signing payload without type prefix
+type unsignedDynamicFeeTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int
+	GasFeeCap  *big.Int
+	Gas        uint64
+	To         *common.Address `rlp:"nil"`
+	Value      *big.Int
+	Data       []byte
+	AccessList types.AccessList
 }
 
 func (cli *CLI) buildDecodeCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:                   "decode <hexRawTransaction> [--rlp]",
+		Use:                   "decode <hexRawTransaction|-> [--file path] [--rlp]",
 		Short:                 "Decode hex raw transaction to json",
-		Args:                  cobra.MinimumNArgs(1),
 		DisableFlagsInUseLine: true,
 		Run: func(cmd *cobra.Command, args []string) {
-
-			b := common.FromHex(args[0])
-			if len(b) == 0 {
-				fmt.Println("convert hex string to bytes error")
+			var rawList []string
+			for _, arg := range args {
+				if arg != "-" {
+					rawList = append(rawList, arg)
+					continue
+				}
+				lines, err := readLines(os.Stdin)
+				if err != nil {
+					fmt.Println("Read stdin error: ", err)
+					return
+				}
+				rawList = append(rawList, lines...)
+			}
+			if cmd.Flags().Changed("file") {
+				fileStr, _ := cmd.Flags().GetString("file")
+				f, err := os.Open(fileStr)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				lines, err := readLines(f)
+				f.Close()
+				if err != nil {
+					fmt.Printf("Read file(%s) error: %v\n", fileStr, err)
+					return
+				}
+				rawList = append(rawList, lines...)
+			}
+			if len(rawList) == 0 {
+				fmt.Println(cmd.UsageString())
 				return
 			}
 
 			onlyRlp, _ := cmd.Flags().GetBool("rlp")
 			compress, _ := cmd.Flags().GetBool("compress")
 
-			tx := new(types.Transaction)
-			err := tx.UnmarshalBinary(b)
+			abiFiles, _ := cmd.Flags().GetStringSlice("abi")
+			decoder, err := cli.newABIDecoder(abiFiles)
 			if err != nil {
-				fmt.Println("Decode Bytes Error: ", err)
+				fmt.Println(err)
 				return
 			}
 
-			if onlyRlp {
-				var out []byte
+			marshal := func(v interface{}) ([]byte, error) {
 				if compress {
-					out, err = json.Marshal(tx)
-				} else {
-					out, err = json.MarshalIndent(tx, "", " ")
+					return json.Marshal(v)
+				}
+				return json.MarshalIndent(v, "", " ")
+			}
+
+			for _, raw := range rawList {
+				b := common.FromHex(raw)
+				if len(b) == 0 {
+					fmt.Println("convert hex string to bytes error")
+					return
 				}
+
+				txData, tx, tx0, err := cli.decodeRawTx(b)
+				if err != nil {
+					fmt.Println("Decode Bytes Error: ", err)
+					return
+				}
+
+				if onlyRlp {
+					out, err := marshal(tx)
+					if err != nil {
+						fmt.Println(err)
+						return
+					}
+					fmt.Println(string(out))
+					continue
+				}
+
+				txData.Call = decoder.DecodeInput(tx.Data())
+
+				out, err := marshal(txData)
 				if err != nil {
 					fmt.Println(err)
 					return
 				}
+				if len(rawList) > 1 && compress {
+					// one json per line for many transactions
+					fmt.Println(string(out))
+					continue
+				}
+				if txData.Signed {
+					fmt.Println("The raw transaction is decoded as follow:")
+				} else {
+					fmt.Println("The unsigned raw transaction is decoded as follow:")
+				}
 				fmt.Println(string(out))
 
-				return
+				out, err = marshal(tx0)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				fmt.Println("The unsigned tx is decoded as follow:")
+				fmt.Println(string(out))
 			}
+		},
+	}
 
-			signer := types.NewLondonSigner(tx.ChainId())
-			from, err := types.Sender(signer, tx)
-			if err != nil {
-				fmt.Println("Sender Error: ", err)
-				return
-			}
+	cmd.Flags().BoolP("compress", "C", false, "Compress the out json")
+	cmd.Flags().Bool("rlp", false, "Only decode rlp")
+	cmd.Flags().String("file", "", "file `path` with one hex raw transaction per line")
+	addABIFlag(cmd)
 
-			var tx0b []byte
-			tx0 := new(types.Transaction)
-			switch tx.Type() {
-			case types.LegacyTxType:
-				tx0b, err = rlp.EncodeToBytes([]interface{}{
-					tx.Nonce(),
-					tx.GasPrice(),
-					tx.Gas(),
-					tx.To(),
-					tx.Value(),
-					tx.Data(),
-					tx.ChainId(), uint(0), uint(0),
-				})
-				tx0 = types.NewTx(&types.LegacyTx{
-					Nonce:    tx.Nonce(),
-					GasPrice: tx.GasPrice(),
-					Gas:      tx.Gas(),
-					To:       tx.To(),
-					Value:    tx.Value(),
-					Data:     tx.Data(),
-				})
-			case types.AccessListTxType:
-				tx0b, err = rlp.EncodeToBytes([]interface{}{
-					tx.ChainId(),
-					tx.Nonce(),
-					tx.GasPrice(),
-					tx.Gas(),
-					tx.To(),
-					tx.Value(),
-					tx.Data(),
-					tx.AccessList(),
-				})
-				tx0b = append([]byte{tx.Type()}, tx0b...)
-
-				tx0 = types.NewTx(&types.AccessListTx{
-					ChainID:    tx.ChainId(),
-					Nonce:      tx.Nonce(),
-					GasPrice:   tx.GasPrice(),
-					Gas:        tx.Gas(),
-					To:         tx.To(),
-					Value:      tx.Value(),
-					Data:       tx.Data(),
-					AccessList: tx.AccessList(),
-				})
-			case types.DynamicFeeTxType:
-				tx0b, err = rlp.EncodeToBytes([]interface{}{
-					tx.ChainId(),
-					tx.Nonce(),
-					tx.GasTipCap(),
-					tx.GasFeeCap(),
-					tx.Gas(),
-					tx.To(),
-					tx.Value(),
-					tx.Data(),
-					tx.AccessList(),
-				})
-				tx0 = types.NewTx(&types.DynamicFeeTx{
-					ChainID:    tx.ChainId(),
-					Nonce:      tx.Nonce(),
-					GasTipCap:  tx.GasTipCap(),
-					GasFeeCap:  tx.GasFeeCap(),
-					Gas:        tx.Gas(),
-					To:         tx.To(),
-					Value:      tx.Value(),
-					Data:       tx.Data(),
-					AccessList: tx.AccessList(),
-				})
-			default:
+	return cmd
+}
 
-			}
+// readLines returns the non-empty lines of r, lines begin with # are
+// treated as comments.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		lines = append(lines, text)
+	}
+	return lines, scanner.Err()
+}
 
-			if err != nil {
-				fmt.Println("tx0 rlp EncodeToBytes error: ", err)
-				return
-			}
+// decodeRawTx decodes a signed raw transaction or an unsigned signing
+// payload. It returns the decoded view, the decoded transaction and the
+// transaction without signature.
+func (cli *CLI) decodeRawTx(b []byte) (*TxData, *types.Transaction, *types.Transaction, error) {
+	var (
+		chainID *big.Int
+		signed  = true
+	)
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(b); err != nil {
+		tx, chainID, err = decodeUnsignedTx(b)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		signed = false
+	} else if V, R, S := tx.RawSignatureValues(); tx.Type() == types.LegacyTxType && R.Sign() == 0 && S.Sign() == 0 {
+		// EIP-155 signing payload [nonce, gasPrice, gas, to, value, data, chainID, 0, 0]
+		chainID = V
+		signed = false
+	} else {
+		chainID = tx.ChainId()
+	}
 
-			tx0h := signer.Hash(tx0)
+	tx0 := tx
+	switch tx.Type() {
+	case types.LegacyTxType:
+		tx0 = types.NewTx(&types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: tx.GasPrice(),
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		})
+	case types.AccessListTxType:
+		tx0 = types.NewTx(&types.AccessListTx{
+			ChainID:    chainID,
+			Nonce:      tx.Nonce(),
+			GasPrice:   tx.GasPrice(),
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		})
+	case types.DynamicFeeTxType:
+		tx0 = types.NewTx(&types.DynamicFeeTx{
+			ChainID:    chainID,
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tx.GasTipCap(),
+			GasFeeCap:  tx.GasFeeCap(),
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		})
+	}
+	if !signed {
+		tx = tx0
+	}
 
-			V, R, S := tx.RawSignatureValues()
-			switch tx.Type() {
-			case types.LegacyTxType:
-				if tx.Protected() {
-					chainIdMul := new(big.Int).Mul(tx.ChainId(), big.NewInt(2))
-					V = new(big.Int).Sub(V, chainIdMul)
-					V.Sub(V, big.NewInt(8))
-				}
+	tx0b, err := unsignedTxPayload(tx0, chainID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("tx0 rlp EncodeToBytes error: %v", err)
+	}
+	tx0h := crypto.Keccak256Hash(tx0b)
 
-			case types.AccessListTxType:
-				// AL txs are defined to use 0 and 1 as their recovery
-				// id, add 27 to become equivalent to unprotected Homestead signatures.
-				V = new(big.Int).Add(V, big.NewInt(27))
-			case types.DynamicFeeTxType:
-				V = new(big.Int).Add(V, big.NewInt(27))
-			default:
-			}
+	txData := &TxData{
+		Type:              tx.Type(),
+		To:                tx.To(),
+		Value:             getWeiAmountTextByUnit(tx.Value(), UnitETH),
+		Data:              hex.EncodeToString(tx.Data()),
+		Nonce:             tx.Nonce(),
+		Price:             tx.GasPrice(),
+		GasLimit:          tx.Gas(),
+		MaxFee:            getWeiAmountTextByUnit(new(big.Int).Mul(tx.GasFeeCap(), new(big.Int).SetUint64(tx.Gas())), UnitETH),
+		AccessList:        tx.AccessList(),
+		ChainID:           chainID,
+		Signed:            signed,
+		Raw:               hexutil.Encode(b),
+		UnsignedRawTx:     hexutil.Encode(tx0b),
+		UnsignedRawTxHash: tx0h.String(),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		txData.GasTipCap = tx.GasTipCap()
+		txData.GasFeeCap = tx.GasFeeCap()
+	}
 
-			pubkey, err := recoverPublicKey(tx0h, R, S, V, true)
-			if err != nil {
-				fmt.Println("recoverPublicKey error: ", err)
-				return
-			}
-			pk, err := crypto.UnmarshalPubkey(pubkey)
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			if from != crypto.PubkeyToAddress(*pk) {
-				fmt.Println("recover public key error")
-				return
-			}
+	if signed {
+		from, err := types.Sender(txSigner(chainID), tx)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Sender Error: %v", err)
+		}
 
-			txData := &TxData{
-				From:              from,
-				To:                tx.To(),
-				Value:             getWeiAmountTextByUnit(tx.Value(), UnitETH),
-				Data:              hex.EncodeToString(tx.Data()),
-				Nonce:             tx.Nonce(),
-				Price:             tx.GasPrice(),
-				GasLimit:          tx.Gas(),
-				V:                 hexutil.Encode(V.Bytes()),
-				R:                 hexutil.Encode(R.Bytes()),
-				S:                 hexutil.Encode(S.Bytes()),
-				Hash:              tx.Hash(),
-				ChainID:           tx.ChainId(),
-				Raw:               hexutil.Encode(b),
-				UnsignedRawTx:     hexutil.Encode(tx0b),
-				UnsignedRawTxHash: tx0h.String(),
-				PublicKey:         hexutil.Encode(pubkey),
+		V, R, S := tx.RawSignatureValues()
+		switch tx.Type() {
+		case types.LegacyTxType:
+			if tx.Protected() {
+				chainIdMul := new(big.Int).Mul(tx.ChainId(), big.NewInt(2))
+				V = new(big.Int).Sub(V, chainIdMul)
+				V.Sub(V, big.NewInt(8))
 			}
 
-			var out []byte
-			if compress {
-				out, err = json.Marshal(txData)
-			} else {
-				out, err = json.MarshalIndent(txData, "", " ")
-			}
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			fmt.Println("The raw transaction is decoded as follow:")
-			fmt.Println(string(out))
+		case types.AccessListTxType:
+			// AL txs are defined to use 0 and 1 as their recovery
+			// id, add 27 to become equivalent to unprotected Homestead signatures.
+			V = new(big.Int).Add(V, big.NewInt(27))
+		case types.DynamicFeeTxType:
+			V = new(big.Int).Add(V, big.NewInt(27))
+		default:
+		}
 
-			if compress {
-				out, err = json.Marshal(tx0)
-			} else {
-				out, err = json.MarshalIndent(tx0, "", " ")
-			}
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			fmt.Println("The unsigned tx is decoded as follow:")
-			fmt.Println(string(out))
+		pubkey, err := recoverPublicKey(tx0h, R, S, V, true)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("recoverPublicKey error: %v", err)
+		}
+		pk, err := crypto.UnmarshalPubkey(pubkey)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if from != crypto.PubkeyToAddress(*pk) {
+			return nil, nil, nil, errors.New("recover public key error")
+		}
 
-			return
-		},
+		hash := tx.Hash()
+		txData.From = &from
+		txData.V = hexutil.Encode(V.Bytes())
+		txData.R = hexutil.Encode(R.Bytes())
+		txData.S = hexutil.Encode(S.Bytes())
+		txData.Hash = &hash
+		txData.PublicKey = hexutil.Encode(pubkey)
 	}
 
-	cmd.Flags().BoolP("compress", "C", false, "Compress the out json")
-	cmd.Flags().Bool("rlp", false, "Only decode rlp")
+	if cli.blockchain == NewChain {
+		newChainID := chainID
+		if newChainID == nil || newChainID.Sign() == 0 {
+			newChainID = DefaultChainID
+		}
+		if txData.From != nil {
+			txData.NewFrom = addressToNew(newChainID.Bytes(), *txData.From)
+		}
+		if txData.To != nil {
+			txData.NewTo = addressToNew(newChainID.Bytes(), *txData.To)
+		}
+	}
 
-	return cmd
+	return txData, tx, tx0, nil
+}
+
+// decodeUnsignedTx decodes the signing payload of a transaction, it returns
+// the transaction and the chain ID in the payload.
+func decodeUnsignedTx(b []byte) (*types.Transaction, *big.Int, error) {
+	if len(b) == 0 {
+		return nil, nil, errors.New("empty payload")
+	}
+	if b[0] > 0x7f {
+		var ltx unsignedLegacyTx
+		if err := rlp.DecodeBytes(b, &ltx); err != nil {
+			return nil, nil, err
+		}
+		if ltx.Zero1 != 0 || ltx.Zero2 != 0 {
+			return nil, nil, errors.New("invalid EIP-155 payload")
+		}
+		chainID := ltx.ChainID
+		if chainID == nil {
+			chainID = new(big.Int)
+		}
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    ltx.Nonce,
+			GasPrice: ltx.GasPrice,
+			Gas:      ltx.Gas,
+			To:       ltx.To,
+			Value:    ltx.Value,
+			Data:     ltx.Data,
+		}), chainID, nil
+	}
+
+	switch b[0] {
+	case types.AccessListTxType:
+		var atx unsignedAccessListTx
+		if err := rlp.DecodeBytes(b[1:], &atx); err != nil {
+			return nil, nil, err
+		}
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    atx.ChainID,
+			Nonce:      atx.Nonce,
+			GasPrice:   atx.GasPrice,
+			Gas:        atx.Gas,
+			To:         atx.To,
+			Value:      atx.Value,
+			Data:       atx.Data,
+			AccessList: atx.AccessList,
+		}), atx.ChainID, nil
+	case types.DynamicFeeTxType:
+		var dtx unsignedDynamicFeeTx
+		if err := rlp.DecodeBytes(b[1:], &dtx); err != nil {
+			return nil, nil, err
+		}
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    dtx.ChainID,
+			Nonce:      dtx.Nonce,
+			GasTipCap:  dtx.GasTipCap,
+			GasFeeCap:  dtx.GasFeeCap,
+			Gas:        dtx.Gas,
+			To:         dtx.To,
+			Value:      dtx.Value,
+			Data:       dtx.Data,
+			AccessList: dtx.AccessList,
+		}), dtx.ChainID, nil
+	}
+
+	return nil, nil, types.ErrTxTypeNotSupported
 }
 
 func recoverPublicKey(sighash common.Hash, R, S, Vb *big.Int, homestead bool) ([]byte, error) {
@@ -1,14 +1,70 @@
 package cli
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
 
 func TestDecode(t *testing.T) {
 	cli := NewCLI()
 
-	cli.TestCommand("balance --help")
+	cli.TestCommand("decode --help")
+
+	testGolden(t, "", "decode.golden",
+		"decode 0xf863820258648252089497549e368acafdcae786bb93d98379f1d1561a298080820802a0768ff39803904e993df858e0d4bbc2d56adf37e804e6804e9e2532d6726c70a4a02e9d8a4cdaf1a8d1b7d99f783162bcd8f0a80084b069f6c69c77f4345c4392f8",
+		"decode 0xe3820258648252089497549e368acafdcae786bb93d98379f1d1561a2980808203ef8080 --rlp",
+	)
+}
+
+func TestDecodeRawTx(t *testing.T) {
+	cli := NewCLI()
 
-	cli.TestCommand("balance 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 -c ./../config.toml")
+	signed := common.FromHex("0xf863820258648252089497549e368acafdcae786bb93d98379f1d1561a298080820802a0768ff39803904e993df858e0d4bbc2d56adf37e804e6804e9e2532d6726c70a4a02e9d8a4cdaf1a8d1b7d99f783162bcd8f0a80084b069f6c69c77f4345c4392f8")
+	txData, _, _, err := cli.decodeRawTx(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !txData.Signed || txData.From == nil {
+		t.Fatal("want signed transaction with sender")
+	}
+	if want := "0x97549e368acafdcae786bb93d98379f1d1561a29"; txData.From.String() != common.HexToAddress(want).String() {
+		t.Errorf("from %s, want %s", txData.From.String(), want)
+	}
 
-	cli.TestCommand("balance 0x01 002 003 0x004 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 -c ./../config.toml")
+	// the unsigned payload of the signed transaction decodes to the same hash
+	unsigned, _, _, err := cli.decodeRawTx(common.FromHex(txData.UnsignedRawTx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unsigned.Signed {
+		t.Fatal("want unsigned transaction")
+	}
+	if unsigned.UnsignedRawTxHash != txData.UnsignedRawTxHash {
+		t.Errorf("unsigned hash %s, want %s", unsigned.UnsignedRawTxHash, txData.UnsignedRawTxHash)
+	}
 
+	// typed unsigned payloads
+	for _, input := range []string{
+		`{"chainId":1007,"nonce":1,"gasPrice":"0x64","gas":"21000","to":"0x97549e368acafdcae786bb93d98379f1d1561a29","accessList":[]}`,
+		`{"chainId":1007,"nonce":1,"maxFeePerGas":"0x64","maxPriorityFeePerGas":"1","gas":"21000"}`,
+		`{"chainId":0,"nonce":1,"gasPrice":"0x64","gas":"21000","to":"0x97549e368acafdcae786bb93d98379f1d1561a29"}`,
+	} {
+		tx, chainID := testTxArgsToTx(t, cli, input)
+		payload, err := unsignedTxPayload(tx, chainID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txData, _, _, err := cli.decodeRawTx(payload)
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		if txData.Type != tx.Type() || txData.ChainID.Cmp(chainID) != 0 {
+			t.Errorf("%s: type %d chainID %v, want %d %v", input, txData.Type, txData.ChainID, tx.Type(), chainID)
+		}
+		if hash := crypto.Keccak256Hash(payload).String(); txData.UnsignedRawTxHash != hash {
+			t.Errorf("%s: hash %s, want %s", input, txData.UnsignedRawTxHash, hash)
+		}
+	}
 }
@@ -0,0 +1,471 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/spf13/cobra"
+)
+
+// jsonBig is a big integer that can be given in JSON as a number,
+// a decimal string or a 0x-prefixed hex string.
+type jsonBig big.Int
+
+func (b *jsonBig) UnmarshalJSON(input []byte) error {
+	str := strings.Trim(string(input), `"`)
+	v, err := parseBigString(str)
+	if err != nil {
+		return err
+	}
+	*b = jsonBig(*v)
+	return nil
+}
+
+func (b *jsonBig) ToInt() *big.Int {
+	if b == nil {
+		return nil
+	}
+	return (*big.Int)(b)
+}
+
+// jsonUint64 is an uint64 that can be given in JSON as a number,
+// a decimal string or a 0x-prefixed hex string.
+type jsonUint64 uint64
+
+func (u *jsonUint64) UnmarshalJSON(input []byte) error {
+	v, err := parseBigString(strings.Trim(string(input), `"`))
+	if err != nil {
+		return err
+	}
+	if !v.IsUint64() {
+		return fmt.Errorf("%s overflows uint64", v.String())
+	}
+	*u = jsonUint64(v.Uint64())
+	return nil
+}
+
+func parseBigString(str string) (*big.Int, error) {
+	if str == "" || str == "null" {
+		return new(big.Int), nil
+	}
+	if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") {
+		if len(str) == 2 {
+			return new(big.Int), nil
+		}
+		v, ok := new(big.Int).SetString(str[2:], 16)
+		if !ok {
+			return nil, fmt.Errorf("invalid hex number %s", str)
+		}
+		return v, nil
+	}
+	v, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal number %s", str)
+	}
+	return v, nil
+}
+
+// txArgs is the JSON transaction accepted by the encode command, the
+// field names follow the JSON-RPC transaction object.
+type txArgs struct {
+	Type                 *jsonUint64       `json:"type"`
+	ChainID              *jsonBig          `json:"chainId"`
+	Nonce                jsonUint64        `json:"nonce"`
+	GasPrice             *jsonBig          `json:"gasPrice"`
+	MaxPriorityFeePerGas *jsonBig          `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas         *jsonBig          `json:"maxFeePerGas"`
+	Gas                  jsonUint64        `json:"gas"`
+	To                   string            `json:"to"`
+	Value                *jsonBig          `json:"value"`
+	Data                 *hexutil.Bytes    `json:"data"`
+	Input                *hexutil.Bytes    `json:"input"`
+	AccessList           *types.AccessList `json:"accessList"`
+
+	// Signature values
+	V *jsonBig `json:"v"`
+	R *jsonBig `json:"r"`
+	S *jsonBig `json:"s"`
+}
+
+// EncodedTx is the result of the encode command
+type EncodedTx struct {
+	Type              uint8           `json:"type"`
+	ChainID           *big.Int        `json:"chainID"`
+	UnsignedRawTx     string          `json:"UnsignedRawTx"`
+	UnsignedRawTxHash common.Hash     `json:"UnsignedRawTxHash"`
+	Raw               string          `json:"raw,omitempty"`
+	Hash              *common.Hash    `json:"hash,omitempty"`
+	From              *common.Address `json:"from,omitempty"`
+}
+
+func (cli *CLI) buildEncodeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "encode <tx.json|-> [--sig signature] [--v v --r r --s s] [--out file]",
+		Short:                 "Encode json transaction to unsigned payload and signed raw transaction",
+		Args:                  cobra.MinimumNArgs(1),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			var (
+				input []byte
+				err   error
+			)
+			if args[0] == "-" {
+				input, err = ioutil.ReadAll(os.Stdin)
+			} else {
+				input, err = ioutil.ReadFile(args[0])
+			}
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			var txa txArgs
+			if err := json.Unmarshal(input, &txa); err != nil {
+				fmt.Println("JSON unmarshal error: ", err)
+				return
+			}
+
+			tx, chainID, err := cli.txArgsToTx(&txa)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			payload, err := unsignedTxPayload(tx, chainID)
+			if err != nil {
+				fmt.Println("Encode unsigned tx error: ", err)
+				return
+			}
+			encoded := &EncodedTx{
+				Type:              tx.Type(),
+				ChainID:           chainID,
+				UnsignedRawTx:     hexutil.Encode(payload),
+				UnsignedRawTxHash: crypto.Keccak256Hash(payload),
+			}
+
+			sig, err := getSignatureFromCobra(cmd, &txa, chainID)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if sig != nil {
+				signTx, err := tx.WithSignature(txSigner(chainID), sig)
+				if err != nil {
+					fmt.Println("Combine signature error: ", err)
+					return
+				}
+				from, err := types.Sender(txSigner(chainID), signTx)
+				if err != nil {
+					fmt.Println("Recover sender error: ", err)
+					return
+				}
+				if cmd.Flags().Changed("from") {
+					fromStr, _ := cmd.Flags().GetString("from")
+					if !common.IsHexAddress(fromStr) {
+						fmt.Println(errFromAddressIllegal)
+						return
+					}
+					if common.HexToAddress(fromStr) != from {
+						fmt.Printf("Error: signature is signed by %s, not %s\n", from.String(), fromStr)
+						return
+					}
+				}
+
+				raw, err := signTx.MarshalBinary()
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				hash := signTx.Hash()
+				encoded.Raw = hexutil.Encode(raw)
+				encoded.Hash = &hash
+				encoded.From = &from
+
+				if cmd.Flags().Changed("out") {
+					outStr, _ := cmd.Flags().GetString("out")
+					if err := saveStringToFile(common.Bytes2Hex(raw), outStr); err != nil {
+						fmt.Println(err)
+						return
+					}
+					defer fmt.Println("Successfully save signed transacion hex to file", outStr)
+				}
+			}
+
+			compress, _ := cmd.Flags().GetBool("compress")
+			var out []byte
+			if compress {
+				out, err = json.Marshal(encoded)
+			} else {
+				out, err = json.MarshalIndent(encoded, "", " ")
+			}
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			fmt.Println(string(out))
+		},
+	}
+
+	cmd.Flags().String("sig", "", "the 65 bytes hex signature [R || S || V] of UnsignedRawTxHash")
+	cmd.Flags().String("v", "", "the signature V value, decimal or hex")
+	cmd.Flags().String("r", "", "the signature R value, decimal or hex")
+	cmd.Flags().String("s", "", "the signature S value, decimal or hex")
+	cmd.Flags().String("from", "", "the expected signer address to check the signature with")
+	cmd.Flags().String("out", "", "file `path` to save signed transaction hex")
+	cmd.Flags().BoolP("compress", "C", false, "Compress the out json")
+
+	return cmd
+}
+
+// txArgsToTx builds an unsigned transaction and returns it together with
+// the chain ID to sign it for. A zero chain ID means an unprotected legacy
+// transaction.
+func (cli *CLI) txArgsToTx(txa *txArgs) (*types.Transaction, *big.Int, error) {
+	chainID := DefaultChainID
+	if txa.ChainID != nil {
+		chainID = txa.ChainID.ToInt()
+	}
+
+	var to *common.Address
+	if txa.To != "" {
+		if common.IsHexAddress(txa.To) {
+			address := common.HexToAddress(txa.To)
+			to = &address
+		} else if cli.blockchain == NewChain && strings.HasPrefix(txa.To, "NEW") {
+			address, err := newToAddress(chainID.Bytes(), txa.To)
+			if err != nil {
+				return nil, nil, fmt.Errorf("convert to address error: %v", err)
+			}
+			to = &address
+		} else {
+			return nil, nil, errToAddressIllegal
+		}
+	}
+
+	var data []byte
+	if txa.Input != nil {
+		data = *txa.Input
+	} else if txa.Data != nil {
+		data = *txa.Data
+	}
+
+	value := new(big.Int)
+	if txa.Value != nil {
+		value = txa.Value.ToInt()
+	}
+
+	var accessList types.AccessList
+	if txa.AccessList != nil {
+		accessList = *txa.AccessList
+	}
+
+	var txType uint64
+	if txa.Type != nil {
+		txType = uint64(*txa.Type)
+	} else if txa.MaxFeePerGas != nil || txa.MaxPriorityFeePerGas != nil {
+		txType = types.DynamicFeeTxType
+	} else if txa.AccessList != nil {
+		txType = types.AccessListTxType
+	}
+
+	switch txType {
+	case types.LegacyTxType:
+		if txa.GasPrice == nil {
+			return nil, nil, errors.New("gasPrice required for legacy transaction")
+		}
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    uint64(txa.Nonce),
+			GasPrice: txa.GasPrice.ToInt(),
+			Gas:      uint64(txa.Gas),
+			To:       to,
+			Value:    value,
+			Data:     data,
+		}), chainID, nil
+	case types.AccessListTxType:
+		if txa.GasPrice == nil {
+			return nil, nil, errors.New("gasPrice required for access list transaction")
+		}
+		if chainID.Sign() == 0 {
+			return nil, nil, errors.New("chainId required for access list transaction")
+		}
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    chainID,
+			Nonce:      uint64(txa.Nonce),
+			GasPrice:   txa.GasPrice.ToInt(),
+			Gas:        uint64(txa.Gas),
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: accessList,
+		}), chainID, nil
+	case types.DynamicFeeTxType:
+		if txa.MaxFeePerGas == nil || txa.MaxPriorityFeePerGas == nil {
+			return nil, nil, errors.New("maxFeePerGas and maxPriorityFeePerGas required for dynamic fee transaction")
+		}
+		if chainID.Sign() == 0 {
+			return nil, nil, errors.New("chainId required for dynamic fee transaction")
+		}
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    chainID,
+			Nonce:      uint64(txa.Nonce),
+			GasTipCap:  txa.MaxPriorityFeePerGas.ToInt(),
+			GasFeeCap:  txa.MaxFeePerGas.ToInt(),
+			Gas:        uint64(txa.Gas),
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: accessList,
+		}), chainID, nil
+	}
+
+	return nil, nil, types.ErrTxTypeNotSupported
+}
+
+// txSigner returns the signer for chainID, a zero chain ID selects the
+// unprotected homestead signer.
+func txSigner(chainID *big.Int) types.Signer {
+	if chainID == nil || chainID.Sign() == 0 {
+		return types.HomesteadSigner{}
+	}
+	return types.NewLondonSigner(chainID)
+}
+
+// unsignedTxPayload returns the payload whose keccak256 hash is signed by
+// the sender of tx on the chain chainID.
+func unsignedTxPayload(tx *types.Transaction, chainID *big.Int) ([]byte, error) {
+	switch tx.Type() {
+	case types.LegacyTxType:
+		if chainID == nil || chainID.Sign() == 0 {
+			return rlp.EncodeToBytes([]interface{}{
+				tx.Nonce(),
+				tx.GasPrice(),
+				tx.Gas(),
+				tx.To(),
+				tx.Value(),
+				tx.Data(),
+			})
+		}
+		return rlp.EncodeToBytes([]interface{}{
+			tx.Nonce(),
+			tx.GasPrice(),
+			tx.Gas(),
+			tx.To(),
+			tx.Value(),
+			tx.Data(),
+			chainID, uint(0), uint(0),
+		})
+	case types.AccessListTxType:
+		b, err := rlp.EncodeToBytes([]interface{}{
+			chainID,
+			tx.Nonce(),
+			tx.GasPrice(),
+			tx.Gas(),
+			tx.To(),
+			tx.Value(),
+			tx.Data(),
+			tx.AccessList(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{tx.Type()}, b...), nil
+	case types.DynamicFeeTxType:
+		b, err := rlp.EncodeToBytes([]interface{}{
+			chainID,
+			tx.Nonce(),
+			tx.GasTipCap(),
+			tx.GasFeeCap(),
+			tx.Gas(),
+			tx.To(),
+			tx.Value(),
+			tx.Data(),
+			tx.AccessList(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{tx.Type()}, b...), nil
+	}
+
+	return nil, types.ErrTxTypeNotSupported
+}
+
+// getSignatureFromCobra returns the 65 bytes [R || S || V] signature with V
+// as recovery id 0 or 1, or nil if no signature is given.
+func getSignatureFromCobra(cmd *cobra.Command, txa *txArgs, chainID *big.Int) ([]byte, error) {
+	if cmd.Flags().Changed("sig") {
+		sigStr, _ := cmd.Flags().GetString("sig")
+		sig := common.FromHex(sigStr)
+		if len(sig) != crypto.SignatureLength {
+			return nil, fmt.Errorf("signature length %d, want %d bytes", len(sig), crypto.SignatureLength)
+		}
+		v, err := recoveryID(new(big.Int).SetUint64(uint64(sig[64])), chainID)
+		if err != nil {
+			return nil, err
+		}
+		sig[64] = v
+		return sig, nil
+	}
+
+	V, R, S := txa.V.ToInt(), txa.R.ToInt(), txa.S.ToInt()
+	for _, f := range []struct {
+		name  string
+		value **big.Int
+	}{{"v", &V}, {"r", &R}, {"s", &S}} {
+		if !cmd.Flags().Changed(f.name) {
+			continue
+		}
+		str, _ := cmd.Flags().GetString(f.name)
+		num, err := parseBigString(str)
+		if err != nil {
+			return nil, fmt.Errorf("signature %s error: %v", f.name, err)
+		}
+		*f.value = num
+	}
+	if V == nil && R == nil && S == nil {
+		return nil, nil
+	}
+	if V == nil || R == nil || S == nil {
+		return nil, errors.New("signature requires all of v, r and s")
+	}
+	if R.BitLen() > 256 || S.BitLen() > 256 {
+		return nil, errors.New("signature r or s overflows 32 bytes")
+	}
+
+	v, err := recoveryID(V, chainID)
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	R.FillBytes(sig[:32])
+	S.FillBytes(sig[32:64])
+	sig[64] = v
+
+	return sig, nil
+}
+
+// recoveryID converts V in any of the forms 0/1, 27/28 or EIP-155
+// chainID*2+35/36 to the recovery id 0 or 1.
+func recoveryID(V, chainID *big.Int) (byte, error) {
+	v := new(big.Int).Set(V)
+	if v.Cmp(big.NewInt(35)) >= 0 && chainID != nil && chainID.Sign() != 0 {
+		v.Sub(v, new(big.Int).Mul(chainID, big.NewInt(2)))
+		v.Sub(v, big.NewInt(35))
+	} else if v.Cmp(big.NewInt(27)) >= 0 {
+		v.Sub(v, big.NewInt(27))
+	}
+	if !v.IsUint64() || v.Uint64() > 1 {
+		return 0, fmt.Errorf("invalid signature v %s", V.String())
+	}
+	return byte(v.Uint64()), nil
+}
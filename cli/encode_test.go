@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestEncode(t *testing.T) {
+	// the signatures of the unsigned tx hashes by the test key
+	legacySig := "edd09819ea53dbc71e322e98f9b5c1a5517bc6096529ae255bdb70bd249451ba0705f35e446da5c3e087574c9ebe8456a0d9596d7eb21873d7f2d0ee2af5714d01"
+	dynamicSig := "6a23bf07f168cc11905ca4ce9d39a2025fda61707bc7952ad39a232bae78472a4f0d3c074864d606e4aa9a1f6257cc0ae0499b994fe3a5a545360a10bc89e3d200"
+
+	testGolden(t, "", "encode.golden",
+		"encode testdata/encode_tx.json",
+		"encode testdata/encode_tx.json --sig "+legacySig+" --from 0xB186E935537A49FdfD394ab0B0110c5479AB51D2",
+		"encode testdata/encode_tx.json --sig "+legacySig+" --from 0x97549e368acafdcae786bb93d98379f1d1561a29",
+		"encode testdata/encode_tx_1559.json -C",
+		"encode testdata/encode_tx_1559.json --sig "+dynamicSig,
+		"encode testdata/nothing.json",
+	)
+}
+
+func TestUnsignedTxPayload(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	cli := NewCLI()
+
+	for _, input := range []string{
+		`{"nonce":1,"gasPrice":"0x64","gas":"21000","to":"0x97549e368acafdcae786bb93d98379f1d1561a29","value":1}`,
+		`{"chainId":0,"nonce":1,"gasPrice":"0x64","gas":"21000","to":"0x97549e368acafdcae786bb93d98379f1d1561a29","value":1}`,
+		`{"chainId":"0x3ef","nonce":1,"gasPrice":"0x64","gas":"21000","to":"0x97549e368acafdcae786bb93d98379f1d1561a29","accessList":[]}`,
+		`{"chainId":1007,"nonce":1,"maxFeePerGas":"0x64","maxPriorityFeePerGas":"1","gas":"21000","input":"0x01"}`,
+	} {
+		tx, chainID := testTxArgsToTx(t, cli, input)
+		payload, err := unsignedTxPayload(tx, chainID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer := txSigner(chainID)
+		if hash := crypto.Keccak256Hash(payload); hash != signer.Hash(tx) {
+			t.Fatalf("%s: payload hash %s, want %s", input, hash.String(), signer.Hash(tx).String())
+		}
+
+		// combine an external signature and recover the sender
+		sig, err := crypto.Sign(crypto.Keccak256(payload), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig[64] += 27
+		v, err := recoveryID(new(big.Int).SetUint64(uint64(sig[64])), chainID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig[64] = v
+		signTx, err := tx.WithSignature(signer, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sender, err := types.Sender(signer, signTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sender != from {
+			t.Fatalf("%s: sender %s, want %s", input, sender.String(), from.String())
+		}
+	}
+}
+
+func testTxArgsToTx(t *testing.T, cli *CLI, input string) (*types.Transaction, *big.Int) {
+	var txa txArgs
+	if err := json.Unmarshal([]byte(input), &txa); err != nil {
+		t.Fatal(err)
+	}
+	tx, chainID, err := cli.txArgsToTx(&txa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx, chainID
+}
+
+func TestRecoveryID(t *testing.T) {
+	chainID := big.NewInt(1007)
+	for _, tt := range []struct {
+		v    int64
+		want byte
+	}{{0, 0}, {1, 1}, {27, 0}, {28, 1}, {1007*2 + 35, 0}, {1007*2 + 36, 1}} {
+		got, err := recoveryID(big.NewInt(tt.v), chainID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("recoveryID(%d) = %d, want %d", tt.v, got, tt.want)
+		}
+	}
+	if _, err := recoveryID(big.NewInt(2), chainID); err == nil {
+		t.Error("recoveryID(2) want error")
+	}
+}
@@ -1,11 +1,34 @@
 package cli
 
-import "testing"
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
 
 func TestFaucet(t *testing.T) {
-	cli := NewCLI()
+	var addresses []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/faucet" {
+			http.NotFound(w, r)
+			return
+		}
+		addresses = append(addresses, r.URL.Query().Get("address"))
+	}))
+	defer s.Close()
 
-	cli.TestCommand("faucet 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 -i https://rpc1.newchain.newtonproject.org")
+	cli := NewCLI()
+	out := cli.TestCommand(fmt.Sprintf("faucet 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 0x01 -i %s", s.URL))
+	if want := "address illegal: 0x01\nGet faucet for 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481\n"; out != want {
+		t.Errorf("output %q, want %q", out, want)
+	}
+	if len(addresses) != 1 || addresses[0] != "0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481" {
+		t.Errorf("faucet requests for %q", addresses)
+	}
 
-	cli.TestCommand("faucet 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 -c ../config.toml")
+	out = cli.TestCommand(fmt.Sprintf("faucet 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 -i %s/404", s.URL))
+	if out != "" {
+		t.Errorf("output %q on the failed request", out)
+	}
 }
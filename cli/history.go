@@ -0,0 +1,537 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/newtonproject/newcommander/index"
+	"github.com/newtonproject/newcommander/tracer"
+	"github.com/spf13/cobra"
+)
+
+const (
+	historyBatchSize = 100 // the number of blocks scanned between two checkpoints
+
+	historyExternal = "external"
+	historyInternal = "internal"
+)
+
+var historyCSVHeader = []string{"blockNumber", "timestamp", "hash", "type", "traceIndex", "from", "to", "value", "fee", "status"}
+
+var errCheckpointMismatch = errors.New("the checkpoint is of another scan")
+
+// HistoryEntry is an external tx or an internal transfer of the scanned addresses
+type HistoryEntry struct {
+	BlockNumber uint64          `json:"blockNumber"`
+	Timestamp   uint64          `json:"timestamp"`
+	Hash        common.Hash     `json:"hash"`
+	Type        string          `json:"type"`
+	TraceIndex  int             `json:"traceIndex,omitempty"`
+	From        common.Address  `json:"from"`
+	To          *common.Address `json:"to"`
+	Value       string          `json:"value"`
+	Fee         string          `json:"fee,omitempty"`
+	Status      string          `json:"status"`
+}
+
+// historyCheckpoint is saved after every batch so an interrupted scan can
+// resume from the next block.
+type historyCheckpoint struct {
+	Addresses []common.Address `json:"addresses"`
+	From      uint64           `json:"from"`
+	To        uint64           `json:"to"`
+	Next      uint64           `json:"next"`
+	Format    string           `json:"format"`
+	Internal  bool             `json:"internal"`
+	Offset    int64            `json:"offset"` // the size of the output written before next
+}
+
+func (c *historyCheckpoint) matches(o *historyCheckpoint) bool {
+	return c.From == o.From && c.To == o.To && c.Format == o.Format &&
+		c.Internal == o.Internal && equalAddresses(c.Addresses, o.Addresses)
+}
+
+func loadHistoryCheckpoint(path string) (*historyCheckpoint, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c historyCheckpoint
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("parse checkpoint %s error: %v", path, err)
+	}
+	return &c, nil
+}
+
+// save writes the checkpoint to a temp file first so it is never left half
+// written.
+func (c *historyCheckpoint) save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// truncateOutput truncates the output file to the offset of the checkpoint
+// and writes from there.
+func truncateOutput(f *os.File, offset int64) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < offset {
+		return fmt.Errorf("the output %s has %d bytes, less than the %d bytes of the checkpoint", f.Name(), info.Size(), offset)
+	}
+	if err := f.Truncate(offset); err != nil {
+		return err
+	}
+	_, err = f.Seek(offset, io.SeekStart)
+	return err
+}
+
+// historyScanner collects the txs of the watched addresses from the blocks.
+type historyScanner struct {
+	client   *ethclient.Client
+	tracer   *tracer.Tracer
+	signer   types.Signer
+	watched  map[common.Address]bool
+	internal bool // trace the contract calls for internal transfers
+}
+
+func (s *historyScanner) isWatched(address *common.Address) bool {
+	return address != nil && s.watched[*address]
+}
+
+// involves reports if any watched address is in the tx or its transfers.
+func (s *historyScanner) involves(tx *index.Tx) bool {
+	for _, address := range tx.Addresses() {
+		if s.watched[address] {
+			return true
+		}
+	}
+	return false
+}
+
+// scanBlock returns the txs of the watched addresses in the block, the
+// contract calls are traced if internal is set.
+func (s *historyScanner) scanBlock(ctx context.Context, number uint64) (*index.Block, error) {
+	block, err := s.client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, fmt.Errorf("get block %d error: %v", number, err)
+	}
+
+	result := &index.Block{Number: number, Hash: block.Hash(), ParentHash: block.ParentHash()}
+	for i, tx := range block.Transactions() {
+		from, err := types.Sender(s.signer, tx)
+		if err != nil {
+			return nil, fmt.Errorf("block %d tx %s: %v", number, tx.Hash().String(), err)
+		}
+		external := s.watched[from] || s.isWatched(tx.To())
+		trace := s.internal && len(tx.Data()) > 0
+		if !external && !trace {
+			continue
+		}
+
+		receipt, err := s.client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("get receipt of %s error: %v", tx.Hash().String(), err)
+		}
+		indexed := &index.Tx{
+			BlockNumber: number,
+			BlockHash:   block.Hash(),
+			Timestamp:   block.Time(),
+			Index:       uint(i),
+			From:        from,
+			BaseFee:     block.BaseFee(),
+			Tx:          tx,
+			Receipt:     receipt,
+		}
+
+		// the internal transfers of a failed tx are reverted
+		if trace && receipt.Status == types.ReceiptStatusSuccessful {
+			calls, err := s.tracer.TraceTransaction(ctx, tx.Hash(), nil)
+			if err != nil {
+				return nil, fmt.Errorf("trace %s error: %v", tx.Hash().String(), err)
+			}
+			indexed.Calls = calls
+		}
+		if !external && !s.involves(indexed) {
+			continue
+		}
+		result.Txs = append(result.Txs, indexed)
+	}
+
+	return result, nil
+}
+
+// scanBlocks scans the blocks concurrently and returns them in order.
+func (s *historyScanner) scanBlocks(ctx context.Context, from, to uint64, concurrency int) ([]*index.Block, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	blocks := make([]*index.Block, to-from+1)
+	numbers := make(chan uint64)
+	var (
+		wg      sync.WaitGroup
+		errOnce sync.Once
+		scanErr error
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range numbers {
+				block, err := s.scanBlock(ctx, n)
+				if err != nil {
+					errOnce.Do(func() {
+						scanErr = err
+						cancel()
+					})
+					continue
+				}
+				blocks[n-from] = block
+			}
+		}()
+	}
+
+	for n := from; n <= to; n++ {
+		select {
+		case numbers <- n:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(numbers)
+	wg.Wait()
+
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return blocks, nil
+}
+
+// historyEntries returns the external tx and the internal transfers of the
+// watched addresses in the tx.
+func historyEntries(tx *index.Tx, watched map[common.Address]bool) []*HistoryEntry {
+	isWatched := func(address *common.Address) bool {
+		return address != nil && watched[*address]
+	}
+	status := "success"
+	if tx.Receipt.Status != types.ReceiptStatusSuccessful {
+		status = "failed"
+	}
+
+	var entries []*HistoryEntry
+	if watched[tx.From] || isWatched(tx.Tx.To()) {
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Receipt.GasUsed), effectiveGasPrice(tx.Tx, tx.BaseFee))
+		entries = append(entries, &HistoryEntry{
+			BlockNumber: tx.BlockNumber,
+			Timestamp:   tx.Timestamp,
+			Hash:        tx.Tx.Hash(),
+			Type:        historyExternal,
+			From:        tx.From,
+			To:          tx.Tx.To(),
+			Value:       getWeiAmountTextByUnit(tx.Tx.Value(), UnitETH),
+			Fee:         getWeiAmountTextByUnit(fee, UnitETH),
+			Status:      status,
+		})
+	}
+	// the first call is the tx itself
+	for i := 1; i < len(tx.Calls); i++ {
+		call := tx.Calls[i]
+		if !call.IsTransfer() || tracer.IsReverted(tx.Calls, i) {
+			continue
+		}
+		if !watched[call.From] && !isWatched(call.To) {
+			continue
+		}
+		entries = append(entries, &HistoryEntry{
+			BlockNumber: tx.BlockNumber,
+			Timestamp:   tx.Timestamp,
+			Hash:        tx.Tx.Hash(),
+			Type:        historyInternal,
+			TraceIndex:  i,
+			From:        call.From,
+			To:          call.To,
+			Value:       getWeiAmountTextByUnit(call.Value, UnitETH),
+			Status:      status,
+		})
+	}
+	return entries
+}
+
+// historyWriter writes the entries in csv or jsonl.
+type historyWriter struct {
+	format string
+	w      *bufio.Writer
+	csv    *csv.Writer
+}
+
+func newHistoryWriter(w io.Writer, format string, header bool) (*historyWriter, error) {
+	hw := &historyWriter{format: format, w: bufio.NewWriter(w)}
+	switch format {
+	case "csv":
+		hw.csv = csv.NewWriter(hw.w)
+		if header {
+			if err := hw.csv.Write(historyCSVHeader); err != nil {
+				return nil, err
+			}
+		}
+	case "jsonl":
+	default:
+		return nil, fmt.Errorf("unsupported format %s, must be csv or jsonl", format)
+	}
+	return hw, nil
+}
+
+func (hw *historyWriter) write(entry *HistoryEntry) error {
+	if hw.csv == nil {
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := hw.w.Write(b); err != nil {
+			return err
+		}
+		return hw.w.WriteByte('\n')
+	}
+
+	to := ""
+	if entry.To != nil {
+		to = entry.To.String()
+	}
+	return hw.csv.Write([]string{
+		strconv.FormatUint(entry.BlockNumber, 10),
+		strconv.FormatUint(entry.Timestamp, 10),
+		entry.Hash.String(),
+		entry.Type,
+		strconv.Itoa(entry.TraceIndex),
+		entry.From.String(),
+		to,
+		entry.Value,
+		entry.Fee,
+		entry.Status,
+	})
+}
+
+func (hw *historyWriter) flush() error {
+	if hw.csv != nil {
+		hw.csv.Flush()
+		if err := hw.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return hw.w.Flush()
+}
+
+func (cli *CLI) buildHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history <address1> [address2]... [--from number] [--to latest] [--internal] [--format csv|jsonl] [-o file] [--resume]",
+		Short: "Scan the blocks for the txs and internal transfers of the addresses",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var addresses []common.Address
+			for _, arg := range args {
+				address, err := cli.toAddress(arg)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				addresses = append(addresses, address)
+			}
+			sortAddresses(addresses)
+
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx := context.Background()
+
+			from, to, err := getBlockRange(ctx, cmd, cli.client)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			format, _ := cmd.Flags().GetString("format")
+			internal, _ := cmd.Flags().GetBool("internal")
+			checkpoint := &historyCheckpoint{
+				Addresses: addresses,
+				From:      from,
+				To:        to,
+				Next:      from,
+				Format:    format,
+				Internal:  internal,
+			}
+
+			// resume from the checkpoint of the output file
+			output, _ := cmd.Flags().GetString("output")
+			checkpointPath := ""
+			if output != "" {
+				checkpointPath = output + ".checkpoint"
+			}
+			resume, _ := cmd.Flags().GetBool("resume")
+			if resume {
+				if checkpointPath == "" {
+					fmt.Println("Error: resume requires the output file")
+					return
+				}
+				saved, err := loadHistoryCheckpoint(checkpointPath)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				// the default range of the saved scan
+				if !cmd.Flags().Changed("from") {
+					checkpoint.From = saved.From
+				}
+				if !cmd.Flags().Changed("to") {
+					checkpoint.To = saved.To
+				}
+				if !saved.matches(checkpoint) {
+					fmt.Println(errCheckpointMismatch)
+					return
+				}
+				checkpoint = saved
+				fmt.Printf("Resume from block %d\n", checkpoint.Next)
+			}
+
+			var (
+				w   io.Writer = os.Stdout
+				out *os.File
+			)
+			if output != "" {
+				flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+				if resume {
+					flag = os.O_WRONLY
+				}
+				f, err := os.OpenFile(output, flag, 0644)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				defer f.Close()
+				if resume {
+					// drop the rows written after the checkpoint was saved
+					if err := truncateOutput(f, checkpoint.Offset); err != nil {
+						fmt.Println(err)
+						return
+					}
+				}
+				w, out = f, f
+			}
+			hw, err := newHistoryWriter(w, format, !resume)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if err := hw.flush(); err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			chainID, err := cli.client.ChainID(ctx)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			t, err := cli.newTracer(cmd)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			watched := make(map[common.Address]bool)
+			for _, address := range addresses {
+				watched[address] = true
+			}
+			scanner := &historyScanner{
+				client:   cli.client,
+				tracer:   t,
+				signer:   types.LatestSignerForChainID(chainID),
+				watched:  watched,
+				internal: internal,
+			}
+
+			var count int
+			for checkpoint.Next <= checkpoint.To {
+				end := checkpoint.Next + historyBatchSize - 1
+				if end > checkpoint.To {
+					end = checkpoint.To
+				}
+				blocks, err := scanner.scanBlocks(ctx, checkpoint.Next, end, getConcurrency(cmd))
+				if err != nil {
+					fmt.Println(err)
+					if checkpointPath != "" {
+						fmt.Printf("Scanned to block %d, run again with --resume to continue\n", checkpoint.Next-1)
+					}
+					return
+				}
+				for _, block := range blocks {
+					for _, tx := range block.Txs {
+						for _, entry := range historyEntries(tx, watched) {
+							if err := hw.write(entry); err != nil {
+								fmt.Println(err)
+								return
+							}
+							count++
+						}
+					}
+				}
+				if err := hw.flush(); err != nil {
+					fmt.Println(err)
+					return
+				}
+
+				checkpoint.Next = end + 1
+				if out != nil {
+					if checkpoint.Offset, err = out.Seek(0, io.SeekCurrent); err != nil {
+						fmt.Println(err)
+						return
+					}
+				}
+				if checkpointPath != "" {
+					if err := checkpoint.save(checkpointPath); err != nil {
+						fmt.Println("Save checkpoint error:", err)
+						return
+					}
+				}
+			}
+
+			if checkpointPath != "" {
+				os.Remove(checkpointPath)
+				fmt.Printf("Scanned blocks %d to %d, %d entries written to %s\n", checkpoint.From, checkpoint.To, count, output)
+			}
+		},
+	}
+
+	addBlockRangeFlags(cmd)
+	cmd.Flags().Bool("internal", false, "trace the contract calls to include the internal transfers")
+	addTracerBackendFlag(cmd)
+	cmd.Flags().String("format", "csv", "the output format, csv or jsonl")
+	cmd.Flags().StringP("output", "o", "", "the file to write, default stdout")
+	cmd.Flags().Bool("resume", false, "resume the scan from the checkpoint of the output file")
+
+	return cmd
+}
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/newtonproject/newcommander/index"
+	"github.com/newtonproject/newcommander/tracer"
+)
+
+func TestHistory(t *testing.T) {
+	testGolden(t, "tx.json", "history.golden",
+		"history 0x7cbdfE7371f56A8f996d9EBa7c66AEddB3f221f3 --from 15 --to 16",
+		"history 0xB186E935537A49FdfD394ab0B0110c5479AB51D2 --from 15 --format jsonl",
+	)
+}
+
+func TestHistoryWriter(t *testing.T) {
+	to := common.HexToAddress("0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54")
+	entry := &HistoryEntry{
+		BlockNumber: 1024,
+		Timestamp:   1600000000,
+		Hash:        common.HexToHash("0x89132e841b36fbe8f2ee3a1ba9bda4a3db5d59bb06458955802c17ba5c1fbd84"),
+		Type:        historyInternal,
+		TraceIndex:  2,
+		From:        common.HexToAddress("0x97549e368acafdcae786bb93d98379f1d1561a29"),
+		To:          &to,
+		Value:       "1.5",
+		Status:      "success",
+	}
+
+	var buf bytes.Buffer
+	hw, err := newHistoryWriter(&buf, "csv", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hw.write(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := hw.flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "blockNumber,timestamp,hash,type,traceIndex,from,to,value,fee,status\n" +
+		"1024,1600000000,0x89132e841b36fbe8f2ee3a1ba9bda4a3db5d59bb06458955802c17ba5c1fbd84,internal,2," +
+		"0x97549E368AcaFdCAE786BB93D98379f1D1561a29," + to.String() + ",1.5,,success\n"
+	if buf.String() != want {
+		t.Errorf("csv\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if hw, err = newHistoryWriter(&buf, "jsonl", false); err != nil {
+		t.Fatal(err)
+	}
+	hw.write(entry)
+	hw.flush()
+	want = `{"blockNumber":1024,"timestamp":1600000000,"hash":"0x89132e841b36fbe8f2ee3a1ba9bda4a3db5d59bb06458955802c17ba5c1fbd84",` +
+		`"type":"internal","traceIndex":2,"from":"0x97549e368acafdcae786bb93d98379f1d1561a29",` +
+		`"to":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54","value":"1.5","status":"success"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("jsonl\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	if _, err := newHistoryWriter(&buf, "xml", true); err == nil {
+		t.Error("want error for unsupported format")
+	}
+}
+
+func TestHistoryCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.csv.checkpoint")
+	c := &historyCheckpoint{
+		Addresses: []common.Address{common.HexToAddress("0x97549e368acafdcae786bb93d98379f1d1561a29")},
+		From:      100,
+		To:        500,
+		Next:      300,
+		Format:    "csv",
+		Offset:    4096,
+	}
+	if err := c.save(path); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := loadHistoryCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Next != 300 || saved.Offset != 4096 || !saved.matches(c) {
+		t.Errorf("loaded checkpoint %+v, want %+v", saved, c)
+	}
+
+	other := *c
+	other.Internal = true
+	if saved.matches(&other) {
+		t.Error("want mismatch of the internal flag")
+	}
+}
+
+func TestTruncateOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.csv")
+	saved := "header\nrow 1\n"
+	// the rows of the batch written before the crash, not in the checkpoint
+	if err := ioutil.WriteFile(path, []byte(saved+"row 2\nrow 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := truncateOutput(f, int64(len(saved)+100)); err == nil {
+		t.Error("want error for the offset beyond the output")
+	}
+	if err := truncateOutput(f, int64(len(saved))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("row 2\nrow 3\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := saved + "row 2\nrow 3\n"; string(b) != want {
+		t.Errorf("output %q, want %q", b, want)
+	}
+}
+
+func TestHistoryEntries(t *testing.T) {
+	var (
+		alice    = common.HexToAddress("0x97549e368acafdcae786bb93d98379f1d1561a29")
+		bob      = common.HexToAddress("0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54")
+		contract = common.HexToAddress("0x82a3a88bc9d6a70c4f3c66534566892eae0cad81")
+		value    = big.NewInt(1e18)
+	)
+	tx := &index.Tx{
+		BlockNumber: 1024,
+		From:        alice,
+		BaseFee:     big.NewInt(1e9),
+		Tx:          types.NewTransaction(0, contract, value, 100000, big.NewInt(2e9), []byte{0x01}),
+		Receipt:     &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 50000},
+		Calls: []*tracer.Tx{
+			{From: alice, To: &contract, Value: value},
+			{From: contract, To: &bob, Value: big.NewInt(5e17)},
+			{From: contract, To: &alice, Value: new(big.Int)},
+		},
+	}
+
+	entries := historyEntries(tx, map[common.Address]bool{alice: true})
+	if len(entries) != 1 {
+		t.Fatalf("%d entries of alice, want 1", len(entries))
+	}
+	if e := entries[0]; e.Type != historyExternal || e.Value != "1" || e.Fee != "0.0001" || e.Status != "success" {
+		t.Errorf("external entry %+v", e)
+	}
+
+	entries = historyEntries(tx, map[common.Address]bool{bob: true})
+	if len(entries) != 1 {
+		t.Fatalf("%d entries of bob, want 1", len(entries))
+	}
+	if e := entries[0]; e.Type != historyInternal || e.TraceIndex != 1 || e.Value != "0.5" || e.Fee != "" {
+		t.Errorf("internal entry %+v", e)
+	}
+}
@@ -0,0 +1,364 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/newtonproject/newcommander/index"
+	"github.com/spf13/cobra"
+)
+
+const (
+	indexDir        = "index"
+	maxIndexRetries = 3 // the max number of rescans of the blocks reorganized during the sync
+)
+
+var errIndexAddressesChanged = errors.New("the index watches other addresses, sync with --reset to rebuild it")
+
+func (cli *CLI) buildIndexCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "index [sync|query]",
+		Short:                 "Index the txs and internal transfers of the addresses in the local database",
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(cli.buildIndexSyncCmd())
+	cmd.AddCommand(cli.buildIndexQueryCmd())
+
+	return cmd
+}
+
+// indexPath returns the path of the index database in the wallet directory.
+func (cli *CLI) indexPath() string {
+	return filepath.Join(cli.walletPath, indexDir)
+}
+
+// rollbackIndex finds the last synced block still in the canonical chain and
+// rolls the index back to it.
+func (cli *CLI) rollbackIndex(ctx context.Context, db *index.DB) error {
+	meta, err := db.Meta()
+	if err != nil {
+		return err
+	} else if meta == nil {
+		return index.ErrNotSynced
+	}
+
+	number := meta.Head
+	for ; number >= meta.From && number > 0; number-- {
+		hash, ok := db.BlockHash(number)
+		if !ok {
+			break
+		}
+		header, err := cli.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return fmt.Errorf("get header %d error: %v", number, err)
+		}
+		if header.Hash() == hash {
+			break
+		}
+	}
+	if number == meta.Head {
+		return nil
+	}
+
+	fmt.Printf("Reorg detected, roll back the index from block %d to %d\n", meta.Head, number)
+	return db.Rollback(number)
+}
+
+// syncIndex indexes the blocks after the head to the target block.
+func (cli *CLI) syncIndex(ctx context.Context, db *index.DB, scanner *historyScanner, target uint64, concurrency int) error {
+	var retries int
+	for {
+		meta, err := db.Meta()
+		if err != nil {
+			return err
+		}
+
+		from := meta.Head + 1
+		if meta.Head < meta.From {
+			// nothing synced yet
+			from = meta.From
+		}
+		if from > target {
+			return nil
+		}
+		to := from + historyBatchSize - 1
+		if to > target {
+			to = target
+		}
+
+		blocks, err := scanner.scanBlocks(ctx, from, to, concurrency)
+		if err != nil {
+			return err
+		}
+
+		// the blocks must extend the synced head, or the chain is reorganized
+		// during the sync
+		parent := meta.HeadHash
+		linked := true
+		for _, block := range blocks {
+			if parent != (common.Hash{}) && block.ParentHash != parent {
+				linked = false
+				break
+			}
+			parent = block.Hash
+		}
+		if !linked {
+			if retries++; retries > maxIndexRetries {
+				return fmt.Errorf("the blocks %d to %d do not link to the synced head", from, to)
+			}
+			if err := cli.rollbackIndex(ctx, db); err != nil {
+				return err
+			}
+			continue
+		}
+		retries = 0
+
+		if err := db.WriteBlocks(blocks); err != nil {
+			return err
+		}
+		var txs int
+		for _, block := range blocks {
+			txs += len(block.Txs)
+		}
+		fmt.Printf("Indexed blocks %d to %d, %d txs\n", from, to, txs)
+	}
+}
+
+func (cli *CLI) buildIndexSyncCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync [address1] [address2]... [--from number] [--internal] [--confirmations 0] [--reset]",
+		Short: "Index the blocks after the last synced one, the addresses are required by the first sync",
+		Run: func(cmd *cobra.Command, args []string) {
+			var addresses []common.Address
+			for _, arg := range args {
+				address, err := cli.toAddress(arg)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				addresses = append(addresses, address)
+			}
+			sortAddresses(addresses)
+
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx := context.Background()
+
+			path := cli.indexPath()
+			if reset, _ := cmd.Flags().GetBool("reset"); reset {
+				if err := os.RemoveAll(path); err != nil {
+					fmt.Println(err)
+					return
+				}
+			}
+			db, err := index.Open(path)
+			if err != nil {
+				fmt.Println("Open index error:", err)
+				return
+			}
+			defer db.Close()
+
+			meta, err := db.Meta()
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if meta == nil {
+				if len(addresses) == 0 {
+					fmt.Println("Error: give the addresses to index")
+					return
+				}
+				from, _, err := getBlockRange(ctx, cmd, cli.client)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				internal, _ := cmd.Flags().GetBool("internal")
+				if err := db.Init(addresses, internal, from); err != nil {
+					fmt.Println(err)
+					return
+				}
+				if meta, err = db.Meta(); err != nil {
+					fmt.Println(err)
+					return
+				}
+			} else if len(addresses) > 0 && !equalAddresses(addresses, meta.Addresses) {
+				fmt.Println(errIndexAddressesChanged)
+				return
+			}
+
+			if err := cli.rollbackIndex(ctx, db); err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			latest, err := cli.client.BlockNumber(ctx)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			confirmations, _ := cmd.Flags().GetUint64("confirmations")
+			if latest < confirmations {
+				fmt.Println("No confirmed block to index")
+				return
+			}
+			target := latest - confirmations
+			if cmd.Flags().Changed("to") {
+				toStr, _ := cmd.Flags().GetString("to")
+				if toStr != "latest" {
+					n, err := strconv.ParseUint(toStr, 10, 64)
+					if err != nil {
+						fmt.Printf("Error: invalid to block number %s\n", toStr)
+						return
+					}
+					if n < target {
+						target = n
+					}
+				}
+			}
+
+			chainID, err := cli.client.ChainID(ctx)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			t, err := cli.newTracer(cmd)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			watched := make(map[common.Address]bool)
+			for _, address := range meta.Addresses {
+				watched[address] = true
+			}
+			scanner := &historyScanner{
+				client:   cli.client,
+				tracer:   t,
+				signer:   types.LatestSignerForChainID(chainID),
+				watched:  watched,
+				internal: meta.Internal,
+			}
+			if err := cli.syncIndex(ctx, db, scanner, target, getConcurrency(cmd)); err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			if meta, err = db.Meta(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			fmt.Printf("Index synced to block %d (%s)\n", meta.Head, meta.HeadHash.String())
+		},
+	}
+
+	addBlockRangeFlags(cmd)
+	cmd.Flags().Bool("internal", false, "trace the contract calls to index the internal transfers, set by the first sync")
+	addTracerBackendFlag(cmd)
+	cmd.Flags().Uint64("confirmations", 0, "the number of blocks behind the latest to index")
+	cmd.Flags().Bool("reset", false, "drop the index and sync from the beginning")
+
+	return cmd
+}
+
+func (cli *CLI) buildIndexQueryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query [address1] [address2]... [--from number] [--to number] [--counterparty address] [--format csv|jsonl]",
+		Short: "Show the indexed txs and internal transfers of the addresses, default all the watched addresses",
+		Run: func(cmd *cobra.Command, args []string) {
+			filter := &index.Filter{}
+			for _, arg := range args {
+				address, err := cli.toAddress(arg)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				filter.Addresses = append(filter.Addresses, address)
+			}
+			if counterparty, _ := cmd.Flags().GetString("counterparty"); counterparty != "" {
+				address, err := cli.toAddress(counterparty)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				filter.Counterparty = &address
+			}
+			filter.FromBlock, _ = cmd.Flags().GetUint64("from")
+			filter.ToBlock, _ = cmd.Flags().GetUint64("to")
+
+			format, _ := cmd.Flags().GetString("format")
+			hw, err := newHistoryWriter(os.Stdout, format, true)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			db, err := index.OpenReadOnly(cli.indexPath())
+			if err == index.ErrNotSynced {
+				fmt.Println(err)
+				return
+			} else if err != nil {
+				fmt.Println("Open index error:", err)
+				return
+			}
+			defer db.Close()
+			meta, err := db.Meta()
+			if err != nil {
+				fmt.Println(err)
+				return
+			} else if meta == nil {
+				fmt.Println(index.ErrNotSynced)
+				return
+			}
+
+			watched := make(map[common.Address]bool)
+			for _, address := range filter.Addresses {
+				watched[address] = true
+			}
+			if len(watched) == 0 {
+				for _, address := range meta.Addresses {
+					watched[address] = true
+				}
+			}
+
+			txs, err := db.Query(filter)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			for _, tx := range txs {
+				for _, entry := range historyEntries(tx, watched) {
+					if filter.Counterparty != nil && !index.IsCounterparty(entry.From, entry.To, watched, *filter.Counterparty) {
+						continue
+					}
+					if err := hw.write(entry); err != nil {
+						fmt.Println(err)
+						return
+					}
+				}
+			}
+			if err := hw.flush(); err != nil {
+				fmt.Println(err)
+			}
+		},
+	}
+
+	cmd.Flags().Uint64("from", 0, "the first block number")
+	cmd.Flags().Uint64("to", 0, "the last block number, default the synced head")
+	cmd.Flags().String("counterparty", "", "only the txs and transfers with the address")
+	cmd.Flags().String("format", "csv", "the output format, csv or jsonl")
+
+	return cmd
+}
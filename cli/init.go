@@ -19,13 +19,17 @@ func (cli *CLI) buildInitCmd() *cobra.Command {
 
 			fmt.Println("Initialize config file")
 
-			prompt := fmt.Sprintf("Enter file in which to save (%s): ", defaultConfigFile)
+			defaultConfigPath := cli.config
+			if defaultConfigPath == "" {
+				defaultConfigPath = defaultConfigFile
+			}
+			prompt := fmt.Sprintf("Enter file in which to save (%s): ", defaultConfigPath)
 			configPath, err := prompt2.Stdin.PromptInput(prompt)
 			if err != nil {
 				fmt.Println("PromptInput err:", err)
 			}
 			if configPath == "" {
-				configPath = defaultConfigFile
+				configPath = defaultConfigPath
 			}
 			cli.config = configPath
 
@@ -1,9 +1,30 @@
 package cli
 
-import "testing"
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
 
 func TestInit(t *testing.T) {
 	cli := NewCLI()
+	// init sets the config of the other tests
+	t.Cleanup(viper.Reset)
 
-	cli.TestCommand("init")
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.toml")
+	cli.TestCommand("init -c " + config + " -w " + filepath.Join(dir, "wallet"))
+	b, err := ioutil.ReadFile(config)
+	if err != nil {
+		t.Fatalf("init does not write the config file: %v", err)
+	}
+	// the defaults of the command flags are not written
+	for _, section := range []string{"[monitor]", "[metrics]"} {
+		if bytes.Contains(b, []byte(section)) {
+			t.Errorf("the config file has the %s section:\n%s", section, b)
+		}
+	}
 }
@@ -0,0 +1,296 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	metricsNamespace       = "newcommander"
+	defaultMetricsListen   = ":9100"
+	defaultMetricsInterval = 15 * time.Second
+	maxMetricsBlocks       = 1000 // the max number of blocks to count the signers of per update
+)
+
+// metricsCollector keeps the chain and account values exported to
+// Prometheus, they are updated periodically and read on scrape.
+type metricsCollector struct {
+	mu sync.Mutex
+
+	up         bool
+	head       *types.Header
+	next       uint64 // the next block to count the signer of
+	sealed     map[common.Address]uint64
+	lastSealed map[common.Address]uint64
+	addresses  []common.Address
+	balances   map[common.Address]*big.Int
+	nonces     map[common.Address]uint64
+}
+
+func newMetricsCollector(addresses []common.Address) *metricsCollector {
+	return &metricsCollector{
+		sealed:     make(map[common.Address]uint64),
+		lastSealed: make(map[common.Address]uint64),
+		addresses:  addresses,
+		balances:   make(map[common.Address]*big.Int),
+		nonces:     make(map[common.Address]uint64),
+	}
+}
+
+// updateMetrics gets the head, counts the signers of the new blocks and gets
+// the balances and nonces of the addresses.
+func (cli *CLI) updateMetrics(ctx context.Context, m *metricsCollector) error {
+	err := cli.collectMetrics(ctx, m)
+	m.mu.Lock()
+	m.up = err == nil
+	m.mu.Unlock()
+	return err
+}
+
+func (cli *CLI) collectMetrics(ctx context.Context, m *metricsCollector) error {
+	if err := cli.BuildClient(); err != nil {
+		return err
+	}
+	head, err := cli.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	number := head.Number.Uint64()
+
+	m.mu.Lock()
+	next := m.next
+	m.mu.Unlock()
+	if next == 0 {
+		// count the signers from the head at start
+		next = number
+	}
+	if next+maxMetricsBlocks <= number {
+		next = number - maxMetricsBlocks + 1
+	}
+	var headers []*types.Header
+	if next <= number {
+		headers, err = fetchHeaders(ctx, cli.client, next, number, defaultConcurrency)
+		if err != nil {
+			return err
+		}
+	}
+
+	balances := make(map[common.Address]*big.Int)
+	nonces := make(map[common.Address]uint64)
+	for _, address := range m.addresses {
+		balance, err := cli.client.BalanceAt(ctx, address, head.Number)
+		if err != nil {
+			return err
+		}
+		nonce, err := cli.client.NonceAt(ctx, address, head.Number)
+		if err != nil {
+			return err
+		}
+		balances[address] = balance
+		nonces[address] = nonce
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, header := range headers {
+		if header.Number.Uint64() == 0 {
+			continue
+		}
+		signer, err := ecrecover(header)
+		if err != nil {
+			continue
+		}
+		m.sealed[signer]++
+		m.lastSealed[signer] = header.Number.Uint64()
+	}
+	m.head = head
+	m.next = number + 1
+	m.balances = balances
+	m.nonces = nonces
+
+	return nil
+}
+
+// writeMetric writes a metric family in the Prometheus text format, values
+// maps the label set to the value.
+func writeMetric(w io.Writer, name, typ, help string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	name = metricsNamespace + "_" + name
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+	labels := make([]string, 0, len(values))
+	for label := range values {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		fmt.Fprintf(w, "%s%s %s\n", name, label, values[label])
+	}
+}
+
+func addressLabel(address common.Address) string {
+	return fmt.Sprintf(`{address="%s"}`, address.String())
+}
+
+// WriteTo writes all the metrics in the Prometheus text format.
+func (m *metricsCollector) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var buf bytes.Buffer
+	up := "0"
+	if m.up {
+		up = "1"
+	}
+	writeMetric(&buf, "up", "gauge", "Whether the last update from the RPC succeeded.", map[string]string{"": up})
+
+	if m.head != nil {
+		age := time.Since(time.Unix(int64(m.head.Time), 0)).Seconds()
+		writeMetric(&buf, "head_block_number", "gauge", "The number of the latest block.",
+			map[string]string{"": m.head.Number.String()})
+		writeMetric(&buf, "head_block_timestamp_seconds", "gauge", "The timestamp of the latest block.",
+			map[string]string{"": fmt.Sprintf("%d", m.head.Time)})
+		writeMetric(&buf, "head_block_age_seconds", "gauge", "The seconds since the latest block.",
+			map[string]string{"": fmt.Sprintf("%.3f", age)})
+	}
+
+	sealed := make(map[string]string)
+	lastSealed := make(map[string]string)
+	for signer, n := range m.sealed {
+		label := fmt.Sprintf(`{signer="%s"}`, signer.String())
+		sealed[label] = fmt.Sprintf("%d", n)
+		lastSealed[label] = fmt.Sprintf("%d", m.lastSealed[signer])
+	}
+	writeMetric(&buf, "signer_sealed_blocks_total", "counter", "The number of blocks sealed by the signer since start.", sealed)
+	writeMetric(&buf, "signer_last_sealed_block", "gauge", "The number of the last block sealed by the signer.", lastSealed)
+
+	balances := make(map[string]string)
+	nonces := make(map[string]string)
+	for _, address := range m.addresses {
+		if balance, ok := m.balances[address]; ok {
+			balances[addressLabel(address)] = getWeiAmountTextByUnit(balance, UnitETH)
+			nonces[addressLabel(address)] = fmt.Sprintf("%d", m.nonces[address])
+		}
+	}
+	writeMetric(&buf, "balance", "gauge", fmt.Sprintf("The balance of the address in %s.", UnitETH), balances)
+	writeMetric(&buf, "nonce", "gauge", "The nonce of the address.", nonces)
+
+	return buf.WriteTo(w)
+}
+
+// metricsAddresses returns the addresses of args, the config and the wallet
+// accounts without duplicates.
+func (cli *CLI) metricsAddresses(args []string) ([]common.Address, error) {
+	var addresses []common.Address
+	seen := make(map[common.Address]bool)
+	add := func(address common.Address) {
+		if !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	for _, addressStr := range append(args, viper.GetStringSlice("metrics.addresses")...) {
+		address, err := cli.toAddress(strings.TrimSpace(addressStr))
+		if err != nil {
+			return nil, err
+		}
+		add(address)
+	}
+	accounts, err := cli.balanceAddresses(nil)
+	if err != nil && (err != errWalletPathEmpty || len(addresses) == 0) {
+		return nil, err
+	}
+	for _, address := range accounts {
+		add(address)
+	}
+
+	return addresses, nil
+}
+
+// metricsConfigKeys are the keys of the [metrics] section of the config file
+// by flag
+var metricsConfigKeys = map[string]string{
+	"listen":   "metrics.listen",
+	"interval": "metrics.interval",
+}
+
+func (cli *CLI) buildServeMetricsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve-metrics [--listen :9100] [--interval 15s] [address1] [address2]...",
+		Short: "Export the chain and account metrics to Prometheus",
+		Run: func(cmd *cobra.Command, args []string) {
+			addresses, err := cli.metricsAddresses(args)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if err := setFlagsFromConfig(cmd, metricsConfigKeys); err != nil {
+				fmt.Println(err)
+				return
+			}
+			interval, _ := cmd.Flags().GetDuration("interval")
+			if interval <= 0 {
+				interval = defaultMetricsInterval
+			}
+			listen, _ := cmd.Flags().GetString("listen")
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			m := newMetricsCollector(addresses)
+			go func() {
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					if err := cli.updateMetrics(ctx, m); err != nil && ctx.Err() == nil {
+						fmt.Println("Update metrics error:", err)
+					}
+					select {
+					case <-ticker.C:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+				m.WriteTo(w)
+			})
+			server := &http.Server{Addr: listen, Handler: mux}
+			go func() {
+				<-ctx.Done()
+				server.Close()
+			}()
+
+			fmt.Printf("Serving metrics of %d addresses on %s/metrics\n", len(addresses), listen)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Println(err)
+			}
+		},
+	}
+
+	cmd.Flags().String("listen", defaultMetricsListen, "the address to serve the metrics on")
+	cmd.Flags().Duration("interval", defaultMetricsInterval, "the interval to update the metrics")
+
+	return cmd
+}
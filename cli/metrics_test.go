@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestMetricsWriteTo(t *testing.T) {
+	address := common.HexToAddress("0x97549e368acafdcae786bb93d98379f1d1561a29")
+	m := newMetricsCollector([]common.Address{address})
+	m.up = true
+	m.head = &types.Header{Number: big.NewInt(100), Time: 1600000000}
+	m.sealed[address] = 3
+	m.lastSealed[address] = 99
+	m.balances[address], _ = new(big.Int).SetString("1500000000000000000", 10)
+	m.nonces[address] = 7
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE newcommander_up gauge\nnewcommander_up 1\n",
+		"newcommander_head_block_number 100\n",
+		"newcommander_head_block_timestamp_seconds 1600000000\n",
+		"# TYPE newcommander_signer_sealed_blocks_total counter\n",
+		`newcommander_signer_sealed_blocks_total{signer="0x97549E368AcaFdCAE786BB93D98379f1D1561a29"} 3` + "\n",
+		`newcommander_signer_last_sealed_block{signer="0x97549E368AcaFdCAE786BB93D98379f1D1561a29"} 99` + "\n",
+		`newcommander_balance{address="0x97549E368AcaFdCAE786BB93D98379f1D1561a29"} 1.5` + "\n",
+		`newcommander_nonce{address="0x97549E368AcaFdCAE786BB93D98379f1D1561a29"} 7` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q in\n%s", want, out)
+		}
+	}
+}
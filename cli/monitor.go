@@ -0,0 +1,380 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+// MonitorStatus is the Nagios plugin status, it is also the exit code
+type MonitorStatus int
+
+const (
+	MonitorOK MonitorStatus = iota
+	MonitorWarning
+	MonitorCritical
+	MonitorUnknown
+)
+
+const (
+	defaultMonitorInterval = 30 * time.Second
+	monitorNotifyTimeout   = 10 * time.Second
+)
+
+func (s MonitorStatus) String() string {
+	switch s {
+	case MonitorOK:
+		return "OK"
+	case MonitorWarning:
+		return "WARNING"
+	case MonitorCritical:
+		return "CRITICAL"
+	}
+	return "UNKNOWN"
+}
+
+// MarshalJSON marshals the status as text
+func (s MonitorStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// worse reports if s is worse than t, a critical check outweighs an unknown one.
+func (s MonitorStatus) worse(t MonitorStatus) bool {
+	rank := map[MonitorStatus]int{MonitorOK: 0, MonitorWarning: 1, MonitorUnknown: 2, MonitorCritical: 3}
+	return rank[s] > rank[t]
+}
+
+// MonitorCheck is the result of a single health check
+type MonitorCheck struct {
+	Name    string        `json:"name"`
+	Status  MonitorStatus `json:"status"`
+	Message string        `json:"message"`
+}
+
+// MonitorReport is the result of all the health checks
+type MonitorReport struct {
+	Time   time.Time       `json:"time"`
+	RPCURL string          `json:"rpcURL"`
+	Status MonitorStatus   `json:"status"`
+	Checks []*MonitorCheck `json:"checks"`
+}
+
+// monitorThresholds is the alert thresholds of the checks
+type monitorThresholds struct {
+	BlockAgeWarn time.Duration
+	BlockAgeCrit time.Duration
+	SignerWindow uint64
+	MinPeersWarn uint64
+	MinPeersCrit uint64
+	LatencyWarn  time.Duration
+	LatencyCrit  time.Duration
+}
+
+func (r *MonitorReport) add(name string, status MonitorStatus, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, &MonitorCheck{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+	if status.worse(r.Status) {
+		r.Status = status
+	}
+}
+
+// Summary returns the Nagios plugin output line
+func (r *MonitorReport) Summary() string {
+	messages := make([]string, 0, len(r.Checks))
+	for _, check := range r.Checks {
+		messages = append(messages, check.Message)
+	}
+	return fmt.Sprintf("%s - %s", r.Status.String(), strings.Join(messages, ", "))
+}
+
+// changed reports if any check status differs from the previous report.
+func (r *MonitorReport) changed(prev *MonitorReport) bool {
+	if prev == nil || prev.Status != r.Status || len(prev.Checks) != len(r.Checks) {
+		return true
+	}
+	for i, check := range r.Checks {
+		if prev.Checks[i].Name != check.Name || prev.Checks[i].Status != check.Status {
+			return true
+		}
+	}
+	return false
+}
+
+// thresholdStatus returns the status of value against the warning and
+// critical thresholds, the higher the worse.
+func thresholdStatus(value, warn, crit time.Duration) MonitorStatus {
+	switch {
+	case crit > 0 && value >= crit:
+		return MonitorCritical
+	case warn > 0 && value >= warn:
+		return MonitorWarning
+	}
+	return MonitorOK
+}
+
+// runMonitorChecks checks the RPC latency, block production, signers, peers
+// and sync status of the node.
+func (cli *CLI) runMonitorChecks(ctx context.Context, th *monitorThresholds) *MonitorReport {
+	report := &MonitorReport{Time: time.Now(), RPCURL: cli.rpcURL, Status: MonitorOK}
+	if err := cli.BuildClient(); err != nil {
+		report.add("rpc", MonitorCritical, "rpc error: %v", err)
+		return report
+	}
+
+	start := time.Now()
+	head, err := cli.client.BlockNumber(ctx)
+	latency := time.Since(start)
+	if err != nil {
+		report.add("rpc", MonitorCritical, "rpc error: %v", err)
+		return report
+	}
+	report.add("rpc", thresholdStatus(latency, th.LatencyWarn, th.LatencyCrit),
+		"rpc latency %s", latency.Round(time.Millisecond))
+
+	header, err := cli.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		report.add("head", MonitorCritical, "get head error: %v", err)
+	} else {
+		age := time.Since(time.Unix(int64(header.Time), 0))
+		if age < 0 {
+			age = 0
+		}
+		report.add("head", thresholdStatus(age, th.BlockAgeWarn, th.BlockAgeCrit),
+			"head %d age %s", header.Number.Uint64(), age.Round(time.Second))
+		head = header.Number.Uint64()
+	}
+
+	cli.checkSigners(ctx, report, head, th.SignerWindow)
+
+	var peers hexutil.Uint64
+	if err := cli.rpcClient.CallContext(ctx, &peers, "net_peerCount"); err != nil {
+		report.add("peers", MonitorUnknown, "get peer count error: %v", err)
+	} else {
+		status := MonitorOK
+		if uint64(peers) < th.MinPeersCrit {
+			status = MonitorCritical
+		} else if uint64(peers) < th.MinPeersWarn {
+			status = MonitorWarning
+		}
+		report.add("peers", status, "%d peers", peers)
+	}
+
+	if progress, err := cli.client.SyncProgress(ctx); err != nil {
+		report.add("sync", MonitorUnknown, "get sync status error: %v", err)
+	} else if progress != nil {
+		report.add("sync", MonitorWarning, "syncing %d/%d", progress.CurrentBlock, progress.HighestBlock)
+	} else {
+		report.add("sync", MonitorOK, "synced")
+	}
+
+	return report
+}
+
+// checkSigners reports the clique signers that sealed no block in the last
+// window blocks. The chain stalls if half of the signers are absent.
+func (cli *CLI) checkSigners(ctx context.Context, report *MonitorReport, head, window uint64) {
+	signers, err := getCliqueSigners(ctx, cli.rpcClient, nil)
+	if err != nil {
+		report.add("signers", MonitorUnknown, "get clique signers error: %v", err)
+		return
+	}
+	if len(signers) == 0 {
+		report.add("signers", MonitorUnknown, "no clique signer")
+		return
+	}
+	if window == 0 {
+		window = uint64(len(signers)) * 2
+	}
+	from := uint64(1)
+	if head >= window {
+		from = head - window + 1
+	}
+	headers, err := fetchHeaders(ctx, cli.client, from, head, defaultConcurrency)
+	if err != nil {
+		report.add("signers", MonitorUnknown, "get headers error: %v", err)
+		return
+	}
+	sealed := make(map[common.Address]bool)
+	for _, header := range headers {
+		if signer, err := ecrecover(header); err == nil {
+			sealed[signer] = true
+		}
+	}
+
+	var absent []string
+	for _, signer := range signers {
+		if !sealed[signer] {
+			absent = append(absent, signer.String())
+		}
+	}
+	switch {
+	case len(absent) == 0:
+		report.add("signers", MonitorOK, "%d/%d signers active", len(signers), len(signers))
+	case len(absent) >= (len(signers)+1)/2:
+		report.add("signers", MonitorCritical, "%d/%d signers absent in %d blocks: %s",
+			len(absent), len(signers), len(headers), strings.Join(absent, " "))
+	default:
+		report.add("signers", MonitorWarning, "%d/%d signers absent in %d blocks: %s",
+			len(absent), len(signers), len(headers), strings.Join(absent, " "))
+	}
+}
+
+// notifyMonitor posts the report to the webhook and runs the script with the
+// report as stdin.
+func notifyMonitor(ctx context.Context, report *MonitorReport, webhook, script string) {
+	b, err := json.Marshal(report)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, monitorNotifyTimeout)
+	defer cancel()
+
+	if webhook != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(b))
+		if err != nil {
+			fmt.Println("Webhook error:", err)
+		} else {
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				fmt.Println("Webhook error:", err)
+			} else {
+				resp.Body.Close()
+				if resp.StatusCode/100 != 2 {
+					fmt.Println("Webhook error:", resp.Status)
+				}
+			}
+		}
+	}
+
+	if script != "" {
+		cmd := exec.CommandContext(ctx, script)
+		cmd.Stdin = bytes.NewReader(b)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(),
+			"MONITOR_STATUS="+report.Status.String(),
+			"MONITOR_SUMMARY="+report.Summary(),
+			"MONITOR_RPCURL="+report.RPCURL)
+		if err := cmd.Run(); err != nil {
+			fmt.Println("Script error:", err)
+		}
+	}
+}
+
+func showMonitorReport(report *MonitorReport, jsonMode bool) {
+	if jsonMode {
+		b, err := json.Marshal(report)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(string(b))
+		return
+	}
+	fmt.Println(report.Summary())
+}
+
+// monitorConfigKeys are the keys of the [monitor] section of the config file
+// by flag
+var monitorConfigKeys = map[string]string{
+	"block-age-warn": "monitor.blockAgeWarn",
+	"block-age-crit": "monitor.blockAgeCrit",
+	"signer-window":  "monitor.signerWindow",
+	"min-peers-warn": "monitor.minPeersWarn",
+	"min-peers-crit": "monitor.minPeersCrit",
+	"latency-warn":   "monitor.latencyWarn",
+	"latency-crit":   "monitor.latencyCrit",
+	"webhook":        "monitor.webhook",
+	"exec":           "monitor.exec",
+}
+
+func (cli *CLI) buildMonitorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "monitor [--loop] [--webhook url] [--exec script]",
+		Short: "Check the chain health with Nagios exit codes, or watch it and alert on change",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := setFlagsFromConfig(cmd, monitorConfigKeys); err != nil {
+				fmt.Println(err)
+				return
+			}
+			th := &monitorThresholds{}
+			th.BlockAgeWarn, _ = cmd.Flags().GetDuration("block-age-warn")
+			th.BlockAgeCrit, _ = cmd.Flags().GetDuration("block-age-crit")
+			th.SignerWindow, _ = cmd.Flags().GetUint64("signer-window")
+			th.MinPeersWarn, _ = cmd.Flags().GetUint64("min-peers-warn")
+			th.MinPeersCrit, _ = cmd.Flags().GetUint64("min-peers-crit")
+			th.LatencyWarn, _ = cmd.Flags().GetDuration("latency-warn")
+			th.LatencyCrit, _ = cmd.Flags().GetDuration("latency-crit")
+			jsonMode, _ := cmd.Flags().GetBool("json")
+
+			loop, _ := cmd.Flags().GetBool("loop")
+			if !loop {
+				report := cli.runMonitorChecks(context.Background(), th)
+				showMonitorReport(report, jsonMode)
+				cli.exit(int(report.Status))
+				return
+			}
+
+			interval, _ := cmd.Flags().GetDuration("interval")
+			if interval <= 0 {
+				interval = defaultMonitorInterval
+			}
+			webhook, _ := cmd.Flags().GetString("webhook")
+			script, _ := cmd.Flags().GetString("exec")
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			var prev *MonitorReport
+			for {
+				report := cli.runMonitorChecks(ctx, th)
+				if ctx.Err() != nil {
+					return
+				}
+				if report.changed(prev) {
+					showMonitorReport(report, jsonMode)
+					if prev != nil || report.Status != MonitorOK {
+						notifyMonitor(ctx, report, webhook, script)
+					}
+				}
+				prev = report
+
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+			}
+		},
+	}
+
+	cmd.Flags().Duration("block-age-warn", 30*time.Second, "warning if the head block is older than this")
+	cmd.Flags().Duration("block-age-crit", 2*time.Minute, "critical if the head block is older than this")
+	cmd.Flags().Uint64("signer-window", 0, "the number of recent blocks every signer must seal one of, default twice the number of signers")
+	cmd.Flags().Uint64("min-peers-warn", 2, "warning if the node has less peers")
+	cmd.Flags().Uint64("min-peers-crit", 1, "critical if the node has less peers")
+	cmd.Flags().Duration("latency-warn", time.Second, "warning if the RPC latency is longer")
+	cmd.Flags().Duration("latency-crit", 5*time.Second, "critical if the RPC latency is longer")
+	cmd.Flags().Bool("loop", false, "keep checking and alert on state change")
+	cmd.Flags().Duration("interval", defaultMonitorInterval, "the interval between checks in loop mode")
+	cmd.Flags().String("webhook", "", "the URL to post the json report to on state change")
+	cmd.Flags().String("exec", "", "the script to run with the json report as stdin on state change")
+	cmd.Flags().Bool("json", false, "show the report in json")
+
+	return cmd
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestMonitorReport(t *testing.T) {
+	report := &MonitorReport{}
+	report.add("rpc", thresholdStatus(10*time.Millisecond, time.Second, 5*time.Second), "rpc latency 10ms")
+	report.add("signers", MonitorUnknown, "no clique signer")
+	if report.Status != MonitorUnknown {
+		t.Fatalf("status %s, want UNKNOWN", report.Status)
+	}
+	report.add("head", thresholdStatus(3*time.Minute, 30*time.Second, 2*time.Minute), "head 1 age 3m0s")
+	if report.Status != MonitorCritical {
+		t.Fatalf("status %s, want CRITICAL", report.Status)
+	}
+	if want := "CRITICAL - rpc latency 10ms, no clique signer, head 1 age 3m0s"; report.Summary() != want {
+		t.Errorf("summary %q, want %q", report.Summary(), want)
+	}
+
+	next := &MonitorReport{Status: report.Status}
+	for _, check := range report.Checks {
+		c := *check
+		c.Message = "changed message"
+		next.Checks = append(next.Checks, &c)
+	}
+	if next.changed(report) {
+		t.Error("want unchanged if only the messages differ")
+	}
+	next.Checks[2].Status = MonitorWarning
+	if !next.changed(report) {
+		t.Error("want changed")
+	}
+}
+
+func TestMonitorConfig(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("monitor.blockAgeWarn", "45s")
+	viper.Set("monitor.latencyWarn", "3s")
+	viper.Set("monitor.minPeersCrit", "x")
+
+	cmd := NewCLI().buildMonitorCmd()
+	if err := cmd.ParseFlags([]string{"--latency-warn", "2s"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := setFlagsFromConfig(cmd, monitorConfigKeys); err == nil {
+		t.Error("want the error of the invalid min-peers-crit")
+	}
+	viper.Set("monitor.minPeersCrit", "4")
+	if err := setFlagsFromConfig(cmd, monitorConfigKeys); err != nil {
+		t.Fatal(err)
+	}
+
+	for flag, want := range map[string]string{
+		"block-age-warn": "45s",  // the config
+		"latency-warn":   "2s",   // the flag over the config
+		"min-peers-crit": "4",    // the config
+		"block-age-crit": "2m0s", // the default
+		"webhook":        "",     // the default
+	} {
+		if got := cmd.Flags().Lookup(flag).Value.String(); got != want {
+			t.Errorf("%s = %q, want %q", flag, got, want)
+		}
+	}
+	if viper.IsSet("monitor.blockAgeCrit") {
+		t.Error("the default of block-age-crit is set in the config")
+	}
+}
@@ -5,11 +5,9 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
-	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -78,8 +76,11 @@ func (cli *CLI) buildPayCmd() *cobra.Command {
 				bNonce = false
 			}
 
-			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-			defer cancel()
+			opts, err := getWaitOptions(cmd)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
 
 			// check balance
 			balance, err := cli.getPendingBalance(cli.tran.From)
@@ -128,12 +129,11 @@ func (cli *CLI) buildPayCmd() *cobra.Command {
 				cli.tran.Nonce, signTx.Hash().String())
 
 			fmt.Println("Waiting for transaction receipt...")
-			_, err = bind.WaitMined(ctx, cli.client, signTx)
-			if err != nil {
-				fmt.Println("WaitMined Error: ", err)
+			if _, err := waitTx(context.Background(), cli.client, signTx, opts); err != nil {
+				fmt.Println("Wait Error: ", err)
 				return
 			}
-			showTransactionReceipt(cli.rpcURL, signTx.Hash().String())
+			cli.showTransactionReceipt(context.Background(), cli.client, signTx.Hash())
 		},
 	}
 
@@ -148,6 +148,7 @@ func (cli *CLI) buildPayCmd() *cobra.Command {
 	cmd.Flags().Uint64P("price", "p", 1, "the gasPrice used for each paid gas (unit in WEI)")
 	cmd.Flags().Uint64P("priceTip", "t", 0, "the gasPriceTip used for each paid gas after 1559 (unit in WEI)")
 	cmd.Flags().Uint64P("nonce", "n", 0, "the number of nonce")
+	addWaitFlags(cmd)
 
 	return cmd
 }
@@ -201,6 +202,10 @@ func (cli *CLI) unlockWallet(account accounts.Account) error {
 	// 	return fmt.Errorf("Error: Failed to unlock account %s (%v)", account.Address.String(), err)
 	// }
 
+	if cli.console && cli.accountUnlocked(account) {
+		return nil
+	}
+
 	var err error
 	walletPassword := cli.tran.Password
 	if walletPassword == "" {
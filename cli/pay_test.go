@@ -1,18 +1,52 @@
 package cli
 
-import "testing"
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/newtonproject/newcommander/internal/rpctest"
+)
 
 func TestPay(t *testing.T) {
-	cli := NewCLI()
+	walletPath := testWallet(t)
 
-	cli.TestCommand("pay 1")
-	cli.TestCommand("pay 2 -u Gwei")
-	cli.TestCommand("pay 2 -c ./../config.toml -w ../")
-	cli.TestCommand("pay 10 --from 0xDB2C9C06E186D58EFe19f213b3d5FaF8B8c99481 --to 0x7cbdfE7371f56A8f996d9EBa7c66AEddB3f221f3 -i http://192.168.168.33:8501 -w ../")
+	testGoldenWallet(t, "tx.json", "pay.golden", walletPath,
+		"pay 1",
+		"pay 2 -u Gwei --from 0xB186E935537A49FdfD394ab0B0110c5479AB51D2 --to 0x7cbdfE7371f56A8f996d9EBa7c66AEddB3f221f3",
+		"pay 100 --from 0xB186E935537A49FdfD394ab0B0110c5479AB51D2 --to 0x7cbdfE7371f56A8f996d9EBa7c66AEddB3f221f3",
+		// no keystore file
+		"pay 1 --from 0x0000000000000000000000000000000000000001 --to 0x7cbdfE7371f56A8f996d9EBa7c66AEddB3f221f3",
+		"pay 1 --from 0xB186E935537A49FdfD394ab0B0110c5479AB51D2 --to 0x7cbdfE7371f56A8f996d9EBa7c66AEddB3f221f3",
+	)
+}
 
-	// no keystore file
-	cli.TestCommand("pay 10 --from 0x0000000000000000000000000000000000000001 --to 0x7cbdfE7371f56A8f996d9EBa7c66AEddB3f221f3 -i http://192.168.168.33:8501 -w ../")
+func TestBatchPay(t *testing.T) {
+	walletPath := testWallet(t)
+	batch := filepath.Join(t.TempDir(), "batch.txt")
+	if err := ioutil.WriteFile(batch, []byte("0x7cbdfE7371f56A8f996d9EBa7c66AEddB3f221f3,1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s := rpctest.NewServerFile(t, filepath.Join("testdata", "tx.json"))
+
+	cli := NewCLI()
+	out := cli.TestCommand(fmt.Sprintf("batchpay %s --from 0xB186E935537A49FdfD394ab0B0110c5479AB51D2 -i %s -w %s", batch, s.URL(), walletPath))
+	if !strings.Contains(out, "nonce too low") {
+		t.Errorf("the batch is not sent:\n%s", out)
+	}
 
-	// walletPath is a file
-	cli.TestCommand("pay 10 -c ./../config.toml -w test.txt")
+	// the account is unlocked in the wallet of the session, so the console
+	// locks it
+	account := accounts.Account{Address: common.HexToAddress("0xB186E935537A49FdfD394ab0B0110c5479AB51D2")}
+	if cli.wallet == nil || !cli.accountUnlocked(account) {
+		t.Error("the account is not unlocked in the wallet of the session")
+	}
+	cli.lockWallet()
+	if cli.accountUnlocked(account) {
+		t.Error("the account is unlocked after lock")
+	}
 }
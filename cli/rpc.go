@@ -3,54 +3,111 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
-	"strconv"
+	"io/ioutil"
+	"math/big"
+	"os"
 	"strings"
 
-	ethereum "github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/spf13/cobra"
 )
 
+// The prefixes of the rpc param types
+const (
+	rpcParamHex = "hex:" // the hex quantity of the decimal or hex number
+	rpcParamDec = "dec:" // the JSON number of the decimal or hex number
+	rpcParamStr = "str:" // the string as it is
+)
+
+// parseRPCParam parses the arg as JSON if it is valid, like objects, arrays,
+// strings, numbers and booleans, otherwise as a string. The type prefixes
+// hex:, dec: and str: force the type.
+func parseRPCParam(arg string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(arg, rpcParamHex):
+		n, err := parseRPCNumber(strings.TrimPrefix(arg, rpcParamHex))
+		if err != nil {
+			return nil, err
+		}
+		return hexutil.EncodeBig(n), nil
+	case strings.HasPrefix(arg, rpcParamDec):
+		n, err := parseRPCNumber(strings.TrimPrefix(arg, rpcParamDec))
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(n.String()), nil
+	case strings.HasPrefix(arg, rpcParamStr):
+		return strings.TrimPrefix(arg, rpcParamStr), nil
+	case json.Valid([]byte(arg)):
+		return json.RawMessage(arg), nil
+	}
+	return arg, nil
+}
+
+func parseRPCNumber(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("invalid number %s, use the decimal or 0x hex number", s)
+	}
+	return n, nil
+}
+
+// readRPCParamsFile reads the JSON array of the params from the file, - for
+// stdin.
+func readRPCParamsFile(file string) ([]interface{}, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if file == "-" {
+		b, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		b, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(b, &raws); err != nil {
+		return nil, fmt.Errorf("the params file %s is not a JSON array: %v", file, err)
+	}
+	params := make([]interface{}, 0, len(raws))
+	for _, raw := range raws {
+		params = append(params, raw)
+	}
+	return params, nil
+}
+
 func (cli *CLI) buildRPCCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:                   "rpc <jsonrpc_method> [jsonrpc_param1] [jsonrpc_param2]",
+		Use:                   "rpc <jsonrpc_method> [jsonrpc_param1] [jsonrpc_param2]... [--params-file file]|batch",
 		Short:                 fmt.Sprintf("%s RPC method", cli.blockchain.String()),
+		Long:                  "Call the RPC method with the params parsed as JSON if valid, otherwise as strings. Use the prefix hex: for the hex quantity, dec: for the decimal number and str: for the string.",
 		Args:                  cobra.MinimumNArgs(1),
 		DisableFlagsInUseLine: true,
 		Run: func(cmd *cobra.Command, args []string) {
 			method := args[0]
 
 			var params []interface{}
+			if file, _ := cmd.Flags().GetString("params-file"); file != "" {
+				if len(args) > 1 {
+					fmt.Println("Error: the params are given by both the args and the params file")
+					return
+				}
+				var err error
+				params, err = readRPCParamsFile(file)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+			}
 			for _, arg := range args[1:] {
-				var param interface{}
-				if arg == "true" {
-					param = true
-				} else if arg == "false" {
-					param = false
-				} else if num, err := strconv.ParseUint(arg, 10, 0); err == nil {
-					param = fmt.Sprintf(`0x%x`, num)
-				} else if strings.HasPrefix(arg, "{") {
-					if method == "eth_estimateGas" || method == "eth_call" {
-						param = ethereum.CallMsg{}
-						err := json.Unmarshal([]byte(arg), &param)
-						if err != nil {
-							fmt.Println("json unmarshal error: ", err)
-							return
-						}
-					} else if method == "eth_sendTransaction" {
-						param = types.Transaction{}
-						err := json.Unmarshal([]byte(arg), &param)
-						if err != nil {
-							fmt.Println("json unmarshal error: ", err)
-							return
-						}
-					} else {
-						param = ""
-					}
-				} else {
-					param = fmt.Sprintf(`%s`, arg)
+				param, err := parseRPCParam(arg)
+				if err != nil {
+					fmt.Println(err)
+					return
 				}
-
 				params = append(params, param)
 			}
 
@@ -58,5 +115,9 @@ func (cli *CLI) buildRPCCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().String("params-file", "", "the JSON array file of the params, - for stdin")
+
+	cmd.AddCommand(cli.buildRPCBatchCmd())
+
 	return cmd
 }
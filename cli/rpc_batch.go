@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/spf13/cobra"
+)
+
+const defaultRPCBatchSize = 100
+
+// rpcBatchEntry is a call in the batch file
+type rpcBatchEntry struct {
+	Line   int               `json:"line"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// rpcBatchResult is the result of a call in the batch file
+type rpcBatchResult struct {
+	*rpcBatchEntry
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// readRPCBatch reads the calls of the JSON lines like
+// {"method":"eth_getBalance","params":["0x...","latest"]}, the blank lines
+// and the lines starting with # are skipped.
+func readRPCBatch(r io.Reader) ([]*rpcBatchEntry, error) {
+	var entries []*rpcBatchEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		entry := &rpcBatchEntry{}
+		if err := json.Unmarshal([]byte(text), entry); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if entry.Method == "" {
+			return nil, fmt.Errorf("line %d: the method is empty", line)
+		}
+		entry.Line = line
+		if entry.Params == nil {
+			entry.Params = []json.RawMessage{}
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// callRPCBatch sends the calls in batches of the size and passes the results
+// to fn in the order of the calls, the failed calls have the Error set.
+func callRPCBatch(ctx context.Context, c *rpc.Client, entries []*rpcBatchEntry, size int, fn func(*rpcBatchResult) error) error {
+	if size <= 0 {
+		size = defaultRPCBatchSize
+	}
+	for start := 0; start < len(entries); start += size {
+		end := start + size
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		batch := make([]rpc.BatchElem, end-start)
+		results := make([]*rpcBatchResult, end-start)
+		for i, entry := range entries[start:end] {
+			args := make([]interface{}, len(entry.Params))
+			for j, param := range entry.Params {
+				args[j] = param
+			}
+			results[i] = &rpcBatchResult{rpcBatchEntry: entry}
+			batch[i] = rpc.BatchElem{Method: entry.Method, Args: args, Result: &results[i].Result}
+		}
+		if err := c.BatchCallContext(ctx, batch); err != nil {
+			return fmt.Errorf("batch of lines %d-%d error: %v", entries[start].Line, entries[end-1].Line, err)
+		}
+
+		for i, result := range results {
+			if batch[i].Error != nil {
+				result.Result = nil
+				result.Error = batch[i].Error.Error()
+			} else if result.Result == nil {
+				result.Result = json.RawMessage("null")
+			}
+			if err := fn(result); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cli *CLI) buildRPCBatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "batch <file.jsonl|-> [--size 100]",
+		Short: "Send the calls of the JSON lines file in JSON-RPC batches, print the results in order",
+		Long: `Send the calls of the JSON lines file in JSON-RPC batches, one call per line like
+  {"method":"eth_getBalance","params":["0xc94770007dda54cF92009BFF0dE90c06F603a09f","latest"]}
+The results are printed in JSON lines in the order of the calls, with the error of every failed call.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var r io.Reader = os.Stdin
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				defer f.Close()
+				r = f
+			}
+			entries, err := readRPCBatch(r)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if len(entries) == 0 {
+				fmt.Println("Error: no call in the batch file")
+				return
+			}
+
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			size, _ := cmd.Flags().GetInt("size")
+
+			err = callRPCBatch(context.Background(), cli.rpcClient, entries, size, func(result *rpcBatchResult) error {
+				b, err := json.Marshal(result)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(b))
+				return nil
+			})
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+		},
+	}
+
+	cmd.Flags().Int("size", defaultRPCBatchSize, "the number of calls in a batch request")
+
+	return cmd
+}
@@ -1,21 +1,91 @@
 package cli
 
-import "testing"
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
 
 func TestRPC(t *testing.T) {
-	cli := NewCLI()
+	testGolden(t, "rpc.json", "rpc.golden",
+		"rpc net_version",
+		"rpc eth_getBlockByNumber latest true",
+		"rpc eth_getBlockByNumber hex:1024 true",
+		"rpc eth_getTransactionByHash 0xcad4299fd6516c7f66cbb5ae70114d9c06d73908a66c9165dbfc1e36fb67d892",
+		"rpc eth_getTransactionReceipt 0xd9f1a3a4c54b6218c848e9a246faa570ea592a9f28d8b14e3ad4035398875de7",
+		"rpc eth_getTransactionCount 0xc94770007dda54cF92009BFF0dE90c06F603a09f latest",
+		"rpc eth_getTransactionCount 0xc94770007dda54cF92009BFF0dE90c06F603a09f hex:1024",
+		"rpc eth_getBalance 0xc94770007dda54cF92009BFF0dE90c06F603a09f latest",
+		"rpc eth_estimateGas {}",
+		`rpc eth_estimateGas {"from":"0xdf9106238879143e914ad78d4ff4b4fa6b3b1648","gasPrice":"0x64","to":"0xdf9106238879143e914ad78d4ff4b4fa6b3b1648","value":"0xde0b6b3a7640000"}`,
+		"rpc eth_gasPrice",
+		"rpc eth_getBalance --params-file testdata/rpc_params.json",
+		`rpc eth_call {"to":"0x0b7789b5f69678f4f2d237cd0e1c815e1cd39ccf","data":"0x18160ddd"} hex:0x400`,
+		"rpc personal_unlockAccount 0xb60e8dd61c5d32be8058bb8eb970870f07233155 str:123456 dec:300",
+		"rpc txpool_status",
+		"rpc debug_traceTransaction 0xcad4299fd6516c7f66cbb5ae70114d9c06d73908a66c9165dbfc1e36fb67d892 {\"tracer\":\"4byteTracer\"}",
+		"rpc eth_getBlockByNumber hex:latest true",
+		`rpc eth_sendTransaction {"from":"0xb60e8dd61c5d32be8058bb8eb970870f07233155","to":"0xd46e8dd67c5d32be8058bb8eb970870f07244567","gas":"0x76c0","gasPrice":"0x9184e72a000","value":"0x9184e72a","data":"0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"}`,
+	)
+}
+
+func TestParseRPCParam(t *testing.T) {
+	for _, tt := range []struct {
+		arg  string
+		want string
+	}{
+		{"latest", `"latest"`},
+		{"0xc94770007dda54cF92009BFF0dE90c06F603a09f", `"0xc94770007dda54cF92009BFF0dE90c06F603a09f"`},
+		{"true", `true`},
+		{"1024", `1024`},
+		{`"1024"`, `"1024"`},
+		{`{"to":"0x01","data":"0x"}`, `{"to":"0x01","data":"0x"}`},
+		{`["0x01",{"a":1}]`, `["0x01",{"a":1}]`},
+		{"hex:1024", `"0x400"`},
+		{"hex:0x400", `"0x400"`},
+		{"dec:0x400", `1024`},
+		{"dec:1024", `1024`},
+		{"str:1024", `"1024"`},
+		{"str:true", `"true"`},
+	} {
+		param, err := parseRPCParam(tt.arg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.arg, err)
+		}
+		b, err := json.Marshal(param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("%s: %s, want %s", tt.arg, b, tt.want)
+		}
+	}
+
+	for _, arg := range []string{"hex:latest", "dec:-1", "hex:1.5"} {
+		if _, err := parseRPCParam(arg); err == nil {
+			t.Errorf("%s: want error", arg)
+		}
+	}
+}
+
+func TestRPCBatch(t *testing.T) {
+	testGolden(t, "rpc.json", "rpc_batch.golden",
+		"rpc batch testdata/rpc_batch.jsonl",
+		"rpc batch testdata/rpc_batch.jsonl --size 4",
+		"rpc batch testdata/rpc_params.json",
+	)
+}
+
+func TestReadRPCBatch(t *testing.T) {
+	entries, err := readRPCBatch(strings.NewReader("{\"method\":\"eth_chainId\"}\n\n# comment\n{\"method\":\"eth_getBalance\",\"params\":[\"0x01\",\"latest\"]}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Line != 1 || len(entries[0].Params) != 0 || entries[1].Line != 4 || len(entries[1].Params) != 2 {
+		t.Errorf("unexpected entries %+v %+v", entries[0], entries[1])
+	}
 
-	cli.TestCommand("rpc net_version")
-	cli.TestCommand("rpc eth_getBlockByNumber latest true")
-	cli.TestCommand("rpc eth_getBlockByNumber 1024 true")
-	cli.TestCommand("rpc eth_getTransactionByHash 0xcad4299fd6516c7f66cbb5ae70114d9c06d73908a66c9165dbfc1e36fb67d892")
-	cli.TestCommand("rpc eth_getTransactionReceipt 0xd9f1a3a4c54b6218c848e9a246faa570ea592a9f28d8b14e3ad4035398875de7")
-	cli.TestCommand("rpc eth_getTransactionCount 0xc94770007dda54cF92009BFF0dE90c06F603a09f latest")
-	cli.TestCommand("rpc eth_getTransactionCount 0xc94770007dda54cF92009BFF0dE90c06F603a09f 1024")
-	cli.TestCommand("rpc eth_getBalance 0xc94770007dda54cF92009BFF0dE90c06F603a09f latest")
-	cli.TestCommand("rpc eth_estimateGas {}")
-	cli.TestCommand(`rpc eth_estimateGas '{"from":"0xdf9106238879143e914ad78d4ff4b4fa6b3b1648","gasPrice":"0x64","to":"0xdf9106238879143e914ad78d4ff4b4fa6b3b1648","value":"0xde0b6b3a7640000"}'`)
-	cli.TestCommand(`rpc eth_estimateGas '{"data":"0xa9059cbb00000000000000000000000082a3a88bc9d6a70c4f3c66534566892eae0cad810000000000000000000000000000000000000000000000000000000000000001","from":"0x82a3a88bc9d6a70c4f3c66534566892eae0cad89","to":"0x0b7789b5f69678f4f2d237cd0e1c815e1cd39ccf","value":"0x0"}'`)
-	cli.TestCommand("rpc eth_gasPrice")
-	cli.TestCommand(`rpc eth_sendTransaction '{"from":"0xb60e8dd61c5d32be8058bb8eb970870f07233155","to":"0xd46e8dd67c5d32be8058bb8eb970870f07244567","gas":"0x76c0","gasPrice":"0x9184e72a000","value":"0x9184e72a","data":"0xd46e8dd67c5d32be8d46e8dd67c5d32be8058bb8eb970870f072445675058bb8eb970870f072445675"}'`)
+	if _, err := readRPCBatch(strings.NewReader("{\"method\":\"eth_chainId\"}\n{\"params\":[]}\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("error %v, want the error of line 2", err)
+	}
 }
@@ -1,23 +1,33 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/newtonproject/newcommander/tracer"
 	"github.com/spf13/cobra"
 )
 
 func (cli *CLI) buildTraceCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:                   "trace <txHash>",
+		Use:                   "trace <txHash> [--tracer name|file.js] [--timeout 10s] [--reexec 128] [--raw] [--tree] [--profile] [--folded file]|block|state",
 		Short:                 "trace tx with hash and get internal txs",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			rpcclient, err := rpc.Dial(cli.rpcURL)
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			t, err := cli.newTracer(cmd)
 			if err != nil {
 				fmt.Println(err)
 				return
@@ -25,27 +35,185 @@ func (cli *CLI) buildTraceCmd() *cobra.Command {
 
 			txHash := common.HexToHash(args[0])
 
-			config := &tracer.TraceConfig{
-				Tracer:  "",
-				Timeout: nil,
-				Reexec:  nil,
+			abiFiles, _ := cmd.Flags().GetStringSlice("abi")
+			decoder, err := cli.newABIDecoder(abiFiles)
+			if err != nil {
+				fmt.Println(err)
+				return
 			}
 
-			txs, err := tracer.TraceTransaction(rpcclient, context.Background(), txHash, config)
+			config, err := traceConfig(cmd)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx := context.Background()
+
+			// the results of the other tracers are not call frames
+			if raw, _ := cmd.Flags().GetBool("raw"); raw || config.Tracer != "" {
+				if config.Tracer == "" {
+					if config.Tracer, err = tracer.LookupTracer(tracer.TracerInternalTx); err != nil {
+						fmt.Println(err)
+						return
+					}
+				}
+				result, err := tracer.TraceRaw(cli.rpcClient, ctx, txHash, config)
+				if err != nil {
+					fmt.Println("Trace error: ", err)
+					return
+				}
+				fmt.Println(string(result))
+				return
+			}
+
+			txs, err := t.TraceTransaction(ctx, txHash, config)
 			if err != nil {
 				fmt.Println("Trace error: ", err)
 				return
 			}
+
+			tree, _ := cmd.Flags().GetBool("tree")
+			profile, _ := cmd.Flags().GetBool("profile")
+			folded, _ := cmd.Flags().GetString("folded")
+			if tree || profile || folded != "" {
+				roots := buildCallTree(txs, decoder)
+				if tree {
+					writeCallTree(os.Stdout, roots)
+				}
+				if profile {
+					if tree {
+						fmt.Println()
+					}
+					showGasProfile(gasProfile(roots))
+				}
+				if folded != "" {
+					f, err := os.Create(folded)
+					if err != nil {
+						fmt.Println(err)
+						return
+					}
+					defer f.Close()
+					if err := writeFoldedStacks(f, roots); err != nil {
+						fmt.Println(err)
+						return
+					}
+				}
+				return
+			}
+
 			for _, tx := range txs {
 				txJson, err := tx.MarshalJSON()
 				if err != nil {
 					fmt.Println(err)
 					return
 				}
+				// the input of create is the init code
+				isCreate := tx.Type == tracer.CallTypeCreate || tx.Type == tracer.CallTypeCreate2
+				if call := decoder.DecodeCall(tx.Input, tx.Output); call != nil && !isCreate {
+					txJson, err = appendJSONField(txJson, "Call", call)
+					if err != nil {
+						fmt.Println(err)
+						return
+					}
+				}
 				fmt.Println(string(txJson))
 			}
 		},
 	}
 
+	addABIFlag(cmd)
+	addTracerBackendFlag(cmd)
+	cmd.Flags().String("tracer", "", fmt.Sprintf("the tracer to print the result of, the built-in %s, a js file or the tracer name of the node like callTracer", strings.Join(tracer.Tracers(), ", ")))
+	cmd.Flags().String("timeout", "", "the timeout of tracing the tx, e.g. 10s")
+	cmd.Flags().Uint64("reexec", 0, "the number of blocks to reexecute to regenerate the missing state, default by the node")
+	cmd.Flags().Bool("raw", false, "print the json result of the tracer as it is")
+	cmd.Flags().Bool("tree", false, "show the calls as an indented tree with the methods, value and gas used")
+	cmd.Flags().Bool("profile", false, "show the gas used by every contract and method")
+	cmd.Flags().String("folded", "", "write the gas of the call stacks to the folded stack file for the flame graph tools")
+
+	cmd.AddCommand(cli.buildTraceBlockCmd())
+	cmd.AddCommand(cli.buildTraceStateCmd())
+
 	return cmd
 }
+
+func addTracerBackendFlag(cmd *cobra.Command) {
+	cmd.Flags().String("backend", tracer.BackendAuto.String(), "the tracing API of the node, auto, js, callTracer or parity")
+}
+
+// newTracer returns the tracer of the --backend flag.
+func (cli *CLI) newTracer(cmd *cobra.Command) (*tracer.Tracer, error) {
+	name, _ := cmd.Flags().GetString("backend")
+	backend, err := tracer.ParseBackend(name)
+	if err != nil {
+		return nil, err
+	}
+	return tracer.New(cli.rpcClient, backend), nil
+}
+
+// resolveTracer returns the code of the built-in tracer or the js file, the
+// other names are passed to the node as they are.
+func resolveTracer(name string) (string, error) {
+	if stringInSlice(name, tracer.Tracers()) {
+		return tracer.LookupTracer(name)
+	}
+	if _, err := os.Stat(name); err == nil || strings.HasSuffix(name, ".js") {
+		code, err := ioutil.ReadFile(name)
+		if err != nil {
+			return "", err
+		}
+		return string(code), nil
+	}
+	return name, nil
+}
+
+// traceConfig returns the config of the --tracer, --timeout and --reexec
+// flags, the internal-tx tracer is left empty as the default.
+func traceConfig(cmd *cobra.Command) (*tracer.TraceConfig, error) {
+	config := &tracer.TraceConfig{}
+	if name, _ := cmd.Flags().GetString("tracer"); name != "" && name != tracer.TracerInternalTx {
+		code, err := resolveTracer(name)
+		if err != nil {
+			return nil, err
+		}
+		config.Tracer = code
+	}
+	if timeout, _ := cmd.Flags().GetString("timeout"); timeout != "" {
+		if _, err := time.ParseDuration(timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout %s", timeout)
+		}
+		config.Timeout = &timeout
+	}
+	if cmd.Flags().Changed("reexec") {
+		reexec, _ := cmd.Flags().GetUint64("reexec")
+		config.Reexec = &reexec
+	}
+	return config, nil
+}
+
+// appendJSONField appends the field key with value v to the JSON object obj
+// and keeps the order of the existing fields.
+func appendJSONField(obj []byte, key string, v interface{}) ([]byte, error) {
+	obj = bytes.TrimSpace(obj)
+	if len(obj) < 2 || obj[0] != '{' || obj[len(obj)-1] != '}' {
+		return nil, errors.New("not a JSON object")
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	field, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(obj)+len(field)+len(value)+2)
+	out = append(out, obj[:len(obj)-1]...)
+	if len(bytes.TrimSpace(obj[1:len(obj)-1])) > 0 {
+		out = append(out, ',')
+	}
+	out = append(out, field...)
+	out = append(out, ':')
+	out = append(out, value...)
+	return append(out, '}'), nil
+}
@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/newtonproject/newcommander/tracer"
+	"github.com/spf13/cobra"
+)
+
+// BlockTransfers is the internal transfers of a transaction in the block
+type BlockTransfers struct {
+	BlockNumber uint64       `json:"blockNumber"`
+	Hash        string       `json:"hash"`
+	Transfers   []*tracer.Tx `json:"transfers"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// parseBlockRange parses the block number, latest, or the range like
+// 100-200.
+func (cli *CLI) parseBlockRange(ctx context.Context, s string) (uint64, uint64, error) {
+	parse := func(s string) (uint64, error) {
+		if s == "latest" {
+			return cli.client.BlockNumber(ctx)
+		}
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid block number %s", s)
+		}
+		return n, nil
+	}
+
+	fromStr, toStr := s, s
+	if i := strings.Index(s, "-"); i >= 0 {
+		fromStr, toStr = s[:i], s[i+1:]
+	}
+	from, err := parse(fromStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := parse(toStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if from > to {
+		return 0, 0, errors.New("from block number is greater than to block number")
+	}
+	return from, to, nil
+}
+
+// splitConcurrency splits the concurrency between the blocks and the txs of
+// each block, so no more than concurrency calls are in flight.
+func splitConcurrency(concurrency int, blocks uint64) (workers, perBlock int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	workers = concurrency
+	if blocks < uint64(workers) {
+		workers = int(blocks)
+	}
+	return workers, concurrency / workers
+}
+
+// traceBlocks traces the blocks concurrently and returns the traces in the
+// block order.
+func traceBlocks(ctx context.Context, t *tracer.Tracer, from, to uint64, config *tracer.TraceConfig, concurrency int) ([][]*tracer.TxTrace, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers, perBlock := splitConcurrency(concurrency, to-from+1)
+
+	results := make([][]*tracer.TxTrace, to-from+1)
+	numbers := make(chan uint64)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		traceErr error
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range numbers {
+				traces, err := t.TraceBlock(ctx, n, config, perBlock)
+				if err != nil {
+					errOnce.Do(func() {
+						traceErr = fmt.Errorf("trace block %d error: %v", n, err)
+						cancel()
+					})
+					continue
+				}
+				results[n-from] = traces
+			}
+		}()
+	}
+
+	for n := from; n <= to; n++ {
+		select {
+		case numbers <- n:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(numbers)
+	wg.Wait()
+
+	if traceErr != nil {
+		return nil, traceErr
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return results, nil
+}
+
+func (cli *CLI) buildTraceBlockCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "block <number|latest|from-to> [--concurrency 8] [--timeout 5s] [--json]",
+		Short:                 "Trace the internal transfers of every tx in the blocks",
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			ctx := context.Background()
+
+			from, to, err := cli.parseBlockRange(ctx, args[0])
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			t, err := cli.newTracer(cmd)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			config, err := traceConfig(cmd)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			jsonMode, _ := cmd.Flags().GetBool("json")
+			concurrency := getConcurrency(cmd)
+
+			for start := from; start <= to; start += historyBatchSize {
+				end := start + historyBatchSize - 1
+				if end > to {
+					end = to
+				}
+				results, err := traceBlocks(ctx, t, start, end, config, concurrency)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+
+				for i, traces := range results {
+					number := start + uint64(i)
+					for _, trace := range traces {
+						transfers := &BlockTransfers{
+							BlockNumber: number,
+							Hash:        trace.Hash.String(),
+							Transfers:   trace.Transfers(),
+							Error:       trace.Error,
+						}
+						if len(transfers.Transfers) == 0 && transfers.Error == "" {
+							continue
+						}
+						if jsonMode {
+							b, err := json.Marshal(transfers)
+							if err != nil {
+								fmt.Println(err)
+								return
+							}
+							fmt.Println(string(b))
+							continue
+						}
+						showBlockTransfers(transfers)
+					}
+				}
+			}
+		},
+	}
+
+	cmd.Flags().Int("concurrency", defaultConcurrency, "the number of blocks or txs traced concurrently")
+	cmd.Flags().String("timeout", "", "the timeout of tracing every call, e.g. 10s")
+	cmd.Flags().Bool("json", false, "show the transfers of every tx in json lines")
+	addTracerBackendFlag(cmd)
+
+	return cmd
+}
+
+func showBlockTransfers(transfers *BlockTransfers) {
+	if transfers.Error != "" {
+		fmt.Printf("Block %d tx %s trace error: %s\n", transfers.BlockNumber, transfers.Hash, transfers.Error)
+		return
+	}
+	fmt.Printf("Block %d tx %s %d internal transfers\n", transfers.BlockNumber, transfers.Hash, len(transfers.Transfers))
+	for _, transfer := range transfers.Transfers {
+		to := "-"
+		if transfer.To != nil {
+			to = transfer.To.String()
+		}
+		fmt.Printf("  %s %s -> %s %s %v\n", transfer.Type, transfer.From.String(), to,
+			getWeiAmountTextUnitByUnit(transfer.Value, UnitETH), transfer.TraceAddress)
+	}
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/newtonproject/newcommander/tracer"
+	"github.com/spf13/cobra"
+)
+
+func (cli *CLI) buildTraceStateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "state <txHash> [--timeout 10s] [--reexec 128] [--json]",
+		Short:                 "Show the balance, nonce, code and storage changes of the accounts touched by the tx",
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+			config, err := traceConfig(cmd)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			diffs, err := tracer.TraceStateDiff(cli.rpcClient, context.Background(), common.HexToHash(args[0]), config)
+			if err != nil {
+				fmt.Println("Trace error: ", err)
+				return
+			}
+
+			if jsonMode, _ := cmd.Flags().GetBool("json"); jsonMode {
+				b, err := json.MarshalIndent(diffs, "", "  ")
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				fmt.Println(string(b))
+				return
+			}
+			writeStateDiffs(os.Stdout, diffs)
+		},
+	}
+
+	cmd.Flags().String("timeout", "", "the timeout of tracing the tx, e.g. 10s")
+	cmd.Flags().Uint64("reexec", 0, "the number of blocks to reexecute to regenerate the missing state, default by the node")
+	cmd.Flags().Bool("json", false, "show the changes in json")
+
+	return cmd
+}
+
+// writeStateDiffs writes the changes of every account, the balances in
+// UnitETH.
+func writeStateDiffs(w io.Writer, diffs []*tracer.AccountDiff) {
+	if len(diffs) == 0 {
+		fmt.Fprintln(w, "No state changes")
+		return
+	}
+	for _, diff := range diffs {
+		status := ""
+		if diff.Created {
+			status = " (created)"
+		} else if diff.Deleted {
+			status = " (deleted)"
+		}
+		fmt.Fprintf(w, "%s%s\n", diff.Address.String(), status)
+
+		if b := diff.Balance; b != nil {
+			delta := b.Delta()
+			sign := "+"
+			if delta.Sign() < 0 {
+				sign = "-"
+			}
+			fmt.Fprintf(w, "  balance: %s -> %s (%s%s)\n",
+				getWeiAmountTextUnitByUnit(b.From.ToInt(), UnitETH),
+				getWeiAmountTextUnitByUnit(b.To.ToInt(), UnitETH),
+				sign, getWeiAmountTextUnitByUnit(new(big.Int).Abs(delta), UnitETH))
+		}
+		if n := diff.Nonce; n != nil {
+			fmt.Fprintf(w, "  nonce: %d -> %d\n", n.From, n.To)
+		}
+		if c := diff.Code; c != nil {
+			fmt.Fprintf(w, "  code: %d bytes -> %d bytes\n", len(c.From), len(c.To))
+		}
+		for _, s := range diff.Storage {
+			fmt.Fprintf(w, "  storage %s: %s -> %s\n", s.Slot.String(), s.From.String(), s.To.String())
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/newtonproject/newcommander/tracer"
+	"github.com/spf13/cobra"
+)
+
+func TestTrace(t *testing.T) {
+	cli := NewCLI()
+
+	cli.TestCommand("trace --help")
+	cli.TestCommand("trace block --help")
+	cli.TestCommand("trace state --help")
+}
+
+func TestTraceConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "my.js")
+	if err := ioutil.WriteFile(file, []byte("{result: function() { return 1; }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	prestate, err := tracer.LookupTracer(tracer.TracerPrestate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		args   []string
+		tracer string
+		err    bool
+	}{
+		{args: nil, tracer: ""},
+		{args: []string{"--tracer", "internal-tx"}, tracer: ""},
+		{args: []string{"--tracer", "prestate"}, tracer: prestate},
+		{args: []string{"--tracer", file}, tracer: "{result: function() { return 1; }}"},
+		{args: []string{"--tracer", "callTracer"}, tracer: "callTracer"},
+		{args: []string{"--tracer", "missing.js"}, err: true},
+		{args: []string{"--timeout", "10"}, err: true},
+	} {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("tracer", "", "")
+		cmd.Flags().String("timeout", "", "")
+		cmd.Flags().Uint64("reexec", 0, "")
+		if err := cmd.Flags().Parse(test.args); err != nil {
+			t.Fatal(err)
+		}
+		config, err := traceConfig(cmd)
+		if test.err {
+			if err == nil {
+				t.Errorf("%v: want error", test.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", test.args, err)
+		}
+		if config.Tracer != test.tracer {
+			t.Errorf("%v: tracer %.40q, want %.40q", test.args, config.Tracer, test.tracer)
+		}
+	}
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("timeout", "", "")
+	cmd.Flags().Uint64("reexec", 0, "")
+	if err := cmd.Flags().Parse([]string{"--timeout", "1m", "--reexec", "0"}); err != nil {
+		t.Fatal(err)
+	}
+	config, err := traceConfig(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Timeout == nil || *config.Timeout != "1m" || config.Reexec == nil || *config.Reexec != 0 {
+		t.Errorf("config %+v, want timeout 1m and reexec 0", config)
+	}
+}
+
+func TestCallTree(t *testing.T) {
+	cli := NewCLI()
+	decoder, err := cli.newABIDecoder(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wallet := common.HexToAddress("0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54")
+	token := common.HexToAddress("0x82a3a88bc9d6a70c4f3c66534566892eae0cad81")
+	user := common.HexToAddress("0x97549e368acafdcae786bb93d98379f1d1561a29")
+	transfer := common.FromHex("0xa9059cbb00000000000000000000000097549e368acafdcae786bb93d98379f1d1561a290000000000000000000000000000000000000000000000000de0b6b3a7640000")
+	txs := []*tracer.Tx{
+		{Type: "call", From: user, To: &wallet, Input: common.FromHex("0x12345678"), Value: big.NewInt(0), Gas: 100000, GasUsed: 60000, TraceAddress: []int{}},
+		{Type: "call", From: wallet, To: &token, Input: transfer, Value: big.NewInt(0), Gas: 50000, GasUsed: 30000, TraceAddress: []int{0}},
+		{Type: "call", From: token, To: &user, Value: big.NewInt(1e18), Gas: 2300, GasUsed: 0, TraceAddress: []int{0, 0}},
+		{Type: "call", From: wallet, To: &token, Input: transfer, Value: big.NewInt(0), Gas: 20000, GasUsed: 20000, Error: "out of gas", TraceAddress: []int{1}},
+	}
+
+	roots := buildCallTree(txs, decoder)
+	if len(roots) != 1 || len(roots[0].children) != 2 || len(roots[0].children[0].children) != 1 {
+		t.Fatal("unexpected call tree")
+	}
+	if gas := roots[0].selfGas(); gas != 10000 {
+		t.Errorf("self gas %d, want 10000", gas)
+	}
+
+	var tree bytes.Buffer
+	writeCallTree(&tree, roots)
+	want := "call " + wallet.String() + " 0x12345678 gas 60000/100000\n" +
+		"  call " + token.String() + " transfer(address,uint256) gas 30000/50000\n" +
+		"    call " + user.String() + " value 1 NEW gas 0/2300\n" +
+		"  call " + token.String() + " transfer(address,uint256) gas 20000/20000 error: out of gas\n"
+	if tree.String() != want {
+		t.Errorf("tree\n%s\nwant\n%s", tree.String(), want)
+	}
+
+	profile := gasProfile(roots)
+	if len(profile) != 3 {
+		t.Fatalf("%d profile entries, want 3", len(profile))
+	}
+	if p := profile[0]; p.Contract != token.String() || p.Method != "transfer(address,uint256)" || p.Calls != 2 || p.SelfGas != 50000 || p.GasUsed != 50000 {
+		t.Errorf("unexpected profile %+v", p)
+	}
+	if p := profile[2]; p.Contract != user.String() || p.Method != "call" || p.SelfGas != 0 {
+		t.Errorf("unexpected profile %+v", p)
+	}
+
+	var folded bytes.Buffer
+	if err := writeFoldedStacks(&folded, roots); err != nil {
+		t.Fatal(err)
+	}
+	root := wallet.String() + ":0x12345678"
+	child := root + ";" + token.String() + ":transfer(address,uint256)"
+	want = root + " 10000\n" + child + " 50000\n" + child + ";" + user.String() + ":call 0\n"
+	if folded.String() != want {
+		t.Errorf("folded\n%s\nwant\n%s", folded.String(), want)
+	}
+}
+
+func TestWriteStateDiffs(t *testing.T) {
+	address := common.HexToAddress("0x97549e368acafdcae786bb93d98379f1d1561a29")
+	diffs := []*tracer.AccountDiff{{
+		Address: address,
+		Balance: &tracer.BalanceChange{From: (*hexutil.Big)(big.NewInt(1e18)), To: (*hexutil.Big)(big.NewInt(5e17))},
+		Nonce:   &tracer.NonceChange{From: 1, To: 2},
+		Storage: []*tracer.StorageChange{{Slot: common.HexToHash("0x01"), From: common.HexToHash("0x05"), To: common.HexToHash("0x06")}},
+	}}
+
+	var out bytes.Buffer
+	writeStateDiffs(&out, diffs)
+	want := address.String() + "\n" +
+		"  balance: 1 NEW -> 0.5 NEW (-0.5 NEW)\n" +
+		"  nonce: 1 -> 2\n" +
+		"  storage " + common.HexToHash("0x01").String() + ": " + common.HexToHash("0x05").String() + " -> " + common.HexToHash("0x06").String() + "\n"
+	if out.String() != want {
+		t.Errorf("state diffs\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestSplitConcurrency(t *testing.T) {
+	tests := []struct {
+		concurrency       int
+		blocks            uint64
+		workers, perBlock int
+	}{
+		{8, 1, 1, 8},
+		{8, 3, 3, 2},
+		{8, 100, 8, 1},
+		{1, 100, 1, 1},
+		{0, 5, 1, 1},
+	}
+	for _, test := range tests {
+		workers, perBlock := splitConcurrency(test.concurrency, test.blocks)
+		if workers != test.workers || perBlock != test.perBlock {
+			t.Errorf("concurrency %d blocks %d: got %d workers %d per block, want %d %d",
+				test.concurrency, test.blocks, workers, perBlock, test.workers, test.perBlock)
+		}
+		if workers*perBlock > test.concurrency && test.concurrency > 0 {
+			t.Errorf("concurrency %d blocks %d: %d calls in flight", test.concurrency, test.blocks, workers*perBlock)
+		}
+	}
+}
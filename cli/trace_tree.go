@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/newtonproject/newcommander/tracer"
+)
+
+// callNode is a call frame in the call tree of the traced transaction
+type callNode struct {
+	tx       *tracer.Tx
+	method   string
+	children []*callNode
+}
+
+// buildCallTree nests the call frames by their trace addresses, the frames
+// are in the order of execution so every caller comes before its calls.
+func buildCallTree(txs []*tracer.Tx, decoder *abiDecoder) []*callNode {
+	var (
+		roots []*callNode
+		stack []*callNode
+	)
+	for _, tx := range txs {
+		node := &callNode{tx: tx, method: callMethod(tx, decoder)}
+		for len(stack) > 0 && len(stack) > len(tx.TraceAddress) {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+		}
+		stack = append(stack, node)
+	}
+	return roots
+}
+
+// callMethod returns the decoded method signature of the call, or the
+// selector if the method is unknown.
+func callMethod(tx *tracer.Tx, decoder *abiDecoder) string {
+	if tx.Type == tracer.CallTypeCreate || tx.Type == tracer.CallTypeCreate2 || tx.Type == tracer.CallTypeSelfDestruct {
+		return ""
+	}
+	if len(tx.Input) < 4 {
+		return ""
+	}
+	if decoder != nil {
+		if call := decoder.DecodeInput(tx.Input); call != nil {
+			return call.Method
+		}
+	}
+	return hexutil.Encode(tx.Input[:4])
+}
+
+// contract returns the called contract, or the created one.
+func (n *callNode) contract() string {
+	if n.tx.To == nil {
+		return "-"
+	}
+	return n.tx.To.String()
+}
+
+// label returns the method of the call, or the call type if there is no
+// method like the value transfers and creations.
+func (n *callNode) label() string {
+	if n.method == "" {
+		return n.tx.Type
+	}
+	return n.method
+}
+
+// selfGas returns the gas used by the frame itself without its calls.
+func (n *callNode) selfGas() uint64 {
+	gas := n.tx.GasUsed
+	for _, child := range n.children {
+		if child.tx.GasUsed > gas {
+			return 0
+		}
+		gas -= child.tx.GasUsed
+	}
+	return gas
+}
+
+// writeCallTree writes the call frames indented by their depths.
+func writeCallTree(w io.Writer, roots []*callNode) {
+	var write func(node *callNode, indent string)
+	write = func(node *callNode, indent string) {
+		tx := node.tx
+		line := fmt.Sprintf("%s%s %s", indent, tx.Type, node.contract())
+		if node.method != "" {
+			line += " " + node.method
+		}
+		if tx.Value != nil && tx.Value.Sign() > 0 {
+			line += " value " + getWeiAmountTextUnitByUnit(tx.Value, UnitETH)
+		}
+		line += fmt.Sprintf(" gas %d/%d", tx.GasUsed, tx.Gas)
+		if tx.Error != "" {
+			line += " error: " + tx.Error
+			if tx.RevertReason != "" {
+				line += " (" + tx.RevertReason + ")"
+			}
+		}
+		fmt.Fprintln(w, line)
+
+		for _, child := range node.children {
+			write(child, indent+"  ")
+		}
+	}
+	for _, root := range roots {
+		write(root, "")
+	}
+}
+
+// gasProfileEntry is the gas used by a method of a contract
+type gasProfileEntry struct {
+	Contract string `json:"contract"`
+	Method   string `json:"method"`
+	Calls    int    `json:"calls"`
+	GasUsed  uint64 `json:"gasUsed"` // with the calls it makes, counted at every level of the recursive calls
+	SelfGas  uint64 `json:"selfGas"`
+}
+
+// gasProfile aggregates the gas by the contract and method, the most
+// expensive first.
+func gasProfile(roots []*callNode) []*gasProfileEntry {
+	entries := make(map[string]*gasProfileEntry)
+	var walk func(node *callNode)
+	walk = func(node *callNode) {
+		method := node.label()
+		key := node.contract() + " " + method
+		entry, ok := entries[key]
+		if !ok {
+			entry = &gasProfileEntry{Contract: node.contract(), Method: method}
+			entries[key] = entry
+		}
+		entry.Calls++
+		entry.GasUsed += node.tx.GasUsed
+		entry.SelfGas += node.selfGas()
+
+		for _, child := range node.children {
+			walk(child)
+		}
+	}
+	for _, root := range roots {
+		walk(root)
+	}
+
+	profile := make([]*gasProfileEntry, 0, len(entries))
+	for _, entry := range entries {
+		profile = append(profile, entry)
+	}
+	sort.Slice(profile, func(i, j int) bool {
+		if profile[i].SelfGas != profile[j].SelfGas {
+			return profile[i].SelfGas > profile[j].SelfGas
+		}
+		if profile[i].Contract != profile[j].Contract {
+			return profile[i].Contract < profile[j].Contract
+		}
+		return profile[i].Method < profile[j].Method
+	})
+	return profile
+}
+
+func showGasProfile(profile []*gasProfileEntry) {
+	fmt.Printf("%-42s  %-40s  %6s  %12s  %12s\n", "Contract", "Method", "Calls", "Self gas", "Total gas")
+	for _, entry := range profile {
+		fmt.Printf("%-42s  %-40s  %6d  %12d  %12d\n", entry.Contract, entry.Method, entry.Calls, entry.SelfGas, entry.GasUsed)
+	}
+}
+
+// writeFoldedStacks writes the self gas of every call stack in the folded
+// format of the flame graph tools, like "0x12..:deposit();0x34..:transfer(address,uint256) 2300".
+func writeFoldedStacks(w io.Writer, roots []*callNode) error {
+	stacks := make(map[string]uint64)
+	var walk func(node *callNode, stack string)
+	walk = func(node *callNode, stack string) {
+		frame := node.contract() + ":" + node.label()
+		if stack != "" {
+			frame = stack + ";" + frame
+		}
+		stacks[frame] += node.selfGas()
+
+		for _, child := range node.children {
+			walk(child, frame)
+		}
+	}
+	for _, root := range roots {
+		walk(root, "")
+	}
+
+	lines := make([]string, 0, len(stacks))
+	for stack, gas := range stacks {
+		lines = append(lines, fmt.Sprintf("%s %d", stack, gas))
+	}
+	sort.Strings(lines)
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
@@ -7,10 +7,10 @@ import (
 	"os"
 	"time"
 
-	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	prompt2 "github.com/ethereum/go-ethereum/console/prompt"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/spf13/cobra"
@@ -177,6 +177,12 @@ func (cli *CLI) buildBroadcastCmd() *cobra.Command {
 				return
 			}
 
+			opts, err := getWaitOptions(cmd)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
 			ctx := context.Background()
 			client, err := rpc.DialContext(ctx, cli.rpcURL)
 			if err != nil {
@@ -188,10 +194,17 @@ func (cli *CLI) buildBroadcastCmd() *cobra.Command {
 				return
 			}
 			fmt.Println("Waiting for transaction receipt...")
-			waitMined(ctx, client, signTx.Hash())
-			showTransactionReceipt(cli.rpcURL, signTx.Hash().String())
+			ethClient := ethclient.NewClient(client)
+			if _, err := waitTx(ctx, ethClient, signTx, opts); err != nil {
+				fmt.Println("Wait Error: ", err)
+				return
+			}
+			cli.showTransactionReceipt(ctx, ethClient, signTx.Hash())
 		},
 	}
+
+	addWaitFlags(broadcastCmd)
+
 	return broadcastCmd
 }
 
@@ -208,40 +221,6 @@ func (cli *CLI) buildSignMesgCmd() *cobra.Command {
 	return signMesgCmd
 }
 
-func waitMined(ctx context.Context, client *rpc.Client, hash common.Hash) {
-	transactionReceipt := func() (*types.Receipt, error) {
-		var r *types.Receipt
-		err := client.CallContext(ctx, &r, "eth_getTransactionReceipt", hash)
-		if err == nil {
-			if r == nil {
-				return nil, ethereum.NotFound
-			}
-		}
-		return r, err
-	}
-
-	queryTicker := time.NewTicker(time.Second)
-	defer queryTicker.Stop()
-
-	for {
-		receipt, err := transactionReceipt()
-		if receipt != nil {
-			break
-		}
-		if err != nil {
-			// logger.Trace("Receipt retrieval failed", "err", err)
-		} else {
-			// logger.Trace("Transaction not yet mined")
-		}
-		// Wait for the next round.
-		select {
-		case <-ctx.Done():
-			break
-		case <-queryTicker.C:
-		}
-	}
-}
-
 func (cli *CLI) saveTranToFile(filepath string) error {
 	tByte, err := cli.tran.MarshalJSON()
 	if err != nil {
@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+)
+
+const (
+	txStatusPending = "pending"
+	txStatusSuccess = "success"
+	txStatusFailed  = "failed"
+)
+
+// TxInfo is the transaction combined with its receipt, values and fees are
+// in UnitETH
+type TxInfo struct {
+	Hash          common.Hash  `json:"hash"`
+	Type          uint8        `json:"type"`
+	Status        string       `json:"status"`
+	BlockNumber   *big.Int     `json:"blockNumber,omitempty"`
+	BlockHash     *common.Hash `json:"blockHash,omitempty"`
+	Timestamp     uint64       `json:"timestamp,omitempty"`
+	Confirmations uint64       `json:"confirmations"`
+
+	From            common.Address  `json:"from"`
+	To              *common.Address `json:"to"`
+	ContractAddress *common.Address `json:"contractAddress,omitempty"`
+
+	// NewChain addresses
+	NewFrom            string `json:"newFrom,omitempty"`
+	NewTo              string `json:"newTo,omitempty"`
+	NewContractAddress string `json:"newContractAddress,omitempty"`
+
+	Nonce             uint64   `json:"nonce"`
+	Value             string   `json:"value"`
+	GasLimit          uint64   `json:"gas"`
+	GasUsed           uint64   `json:"gasUsed,omitempty"`
+	GasPrice          *big.Int `json:"gasPrice"`
+	GasTipCap         *big.Int `json:"maxPriorityFeePerGas,omitempty"`
+	GasFeeCap         *big.Int `json:"maxFeePerGas,omitempty"`
+	EffectiveGasPrice *big.Int `json:"effectiveGasPrice,omitempty"`
+	Fee               string   `json:"fee,omitempty"`
+
+	Data hexutil.Bytes `json:"data"`
+	Call *DecodedCall  `json:"call,omitempty"`
+	Logs []*DecodedLog `json:"logs,omitempty"`
+}
+
+func (cli *CLI) buildTxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "tx [show]",
+		Short:                 "Show the transaction of " + cli.blockchain.String(),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(cli.buildTxShowCmd())
+
+	return cmd
+}
+
+func (cli *CLI) buildTxShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "show <txHash> [--json] [--abi abi.json]",
+		Short:                 "Show the transaction with the receipt, fee and decoded logs",
+		Args:                  cobra.MinimumNArgs(1),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			jsonMode, _ := cmd.Flags().GetBool("json")
+			abiFiles, _ := cmd.Flags().GetStringSlice("abi")
+			decoder, err := cli.newABIDecoder(abiFiles)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			if err := cli.BuildClient(); err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			ctx := context.Background()
+			for _, arg := range args {
+				b, err := hexutil.Decode(arg)
+				if err != nil || len(b) != common.HashLength {
+					fmt.Println("Error: invalid transaction hash", arg)
+					return
+				}
+
+				info, err := cli.getTxInfo(ctx, cli.client, common.BytesToHash(b), decoder)
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				if jsonMode {
+					infoJSON, err := json.MarshalIndent(info, "", "  ")
+					if err != nil {
+						fmt.Println(err)
+						return
+					}
+					fmt.Println(string(infoJSON))
+					continue
+				}
+				showTxInfo(info)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "show the transaction in json")
+	addABIFlag(cmd)
+
+	return cmd
+}
+
+// getTxInfo gets the transaction and its receipt if it is mined.
+func (cli *CLI) getTxInfo(ctx context.Context, client *ethclient.Client, hash common.Hash, decoder *abiDecoder) (*TxInfo, error) {
+	tx, isPending, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("transaction %s not found", hash.String())
+		}
+		return nil, err
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &TxInfo{
+		Hash:     hash,
+		Type:     tx.Type(),
+		Status:   txStatusPending,
+		To:       tx.To(),
+		Nonce:    tx.Nonce(),
+		Value:    getWeiAmountTextByUnit(tx.Value(), UnitETH),
+		GasLimit: tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Data:     tx.Data(),
+		Call:     decoder.DecodeInput(tx.Data()),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		info.GasTipCap = tx.GasTipCap()
+		info.GasFeeCap = tx.GasFeeCap()
+	}
+
+	var receipt *types.Receipt
+	if !isPending {
+		receipt, err = client.TransactionReceipt(ctx, hash)
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+	}
+	if receipt == nil {
+		from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+		if err != nil {
+			return nil, err
+		}
+		info.From = from
+	} else {
+		from, err := client.TransactionSender(ctx, tx, receipt.BlockHash, receipt.TransactionIndex)
+		if err != nil {
+			return nil, err
+		}
+		info.From = from
+
+		info.Status = txStatusSuccess
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			info.Status = txStatusFailed
+		}
+		info.BlockNumber = receipt.BlockNumber
+		info.BlockHash = &receipt.BlockHash
+		if receipt.ContractAddress != (common.Address{}) {
+			info.ContractAddress = &receipt.ContractAddress
+		}
+		info.GasUsed = receipt.GasUsed
+		for _, log := range receipt.Logs {
+			info.Logs = append(info.Logs, decoder.DecodeLog(log))
+		}
+
+		header, err := client.HeaderByHash(ctx, receipt.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+		info.Timestamp = header.Time
+		info.EffectiveGasPrice = effectiveGasPrice(tx, header.BaseFee)
+		info.Fee = getWeiAmountTextByUnit(
+			new(big.Int).Mul(info.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed)), UnitETH)
+
+		info.Confirmations, err = txConfirmations(ctx, client, receipt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cli.blockchain == NewChain {
+		info.NewFrom = addressToNew(chainID.Bytes(), info.From)
+		if info.To != nil {
+			info.NewTo = addressToNew(chainID.Bytes(), *info.To)
+		}
+		if info.ContractAddress != nil {
+			info.NewContractAddress = addressToNew(chainID.Bytes(), *info.ContractAddress)
+		}
+		for _, log := range info.Logs {
+			log.NewAddress = addressToNew(chainID.Bytes(), log.Address)
+		}
+	}
+
+	return info, nil
+}
+
+// effectiveGasPrice returns the gas price paid by the transaction in a
+// block with the base fee.
+func effectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil || tx.Type() != types.DynamicFeeTxType {
+		return tx.GasPrice()
+	}
+	price := new(big.Int).Add(baseFee, tx.GasTipCap())
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		return tx.GasFeeCap()
+	}
+	return price
+}
+
+// showTransactionReceipt shows the mined transaction with its receipt.
+func (cli *CLI) showTransactionReceipt(ctx context.Context, client *ethclient.Client, hash common.Hash) {
+	decoder, err := cli.newABIDecoder(nil)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	info, err := cli.getTxInfo(ctx, client, hash, decoder)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	showTxInfo(info)
+}
+
+func showTxInfo(info *TxInfo) {
+	withNew := func(address common.Address, newAddress string) string {
+		if newAddress == "" {
+			return address.String()
+		}
+		return fmt.Sprintf("%s (%s)", address.String(), newAddress)
+	}
+
+	fmt.Println("Hash:           ", info.Hash.String())
+	fmt.Println("Status:         ", info.Status)
+	if info.BlockNumber != nil {
+		fmt.Printf("Block:           %s (%s)\n", info.BlockNumber.String(), info.BlockHash.String())
+		fmt.Println("Time:           ", time.Unix(int64(info.Timestamp), 0).Format(time.RFC3339))
+		fmt.Println("Confirmations:  ", info.Confirmations)
+	}
+	fmt.Println("From:           ", withNew(info.From, info.NewFrom))
+	if info.To != nil {
+		fmt.Println("To:             ", withNew(*info.To, info.NewTo))
+	}
+	if info.ContractAddress != nil {
+		fmt.Println("Contract:       ", withNew(*info.ContractAddress, info.NewContractAddress))
+	}
+	fmt.Printf("Value:           %s %s\n", info.Value, UnitETH)
+	fmt.Println("Nonce:          ", info.Nonce)
+	fmt.Println("Type:           ", info.Type)
+	fmt.Println("Gas limit:      ", info.GasLimit)
+	if info.GasFeeCap != nil {
+		fmt.Printf("Max fee:         %s %s\n", info.GasFeeCap.String(), UnitWEI)
+		fmt.Printf("Priority fee:    %s %s\n", info.GasTipCap.String(), UnitWEI)
+	} else {
+		fmt.Printf("Gas price:       %s %s\n", info.GasPrice.String(), UnitWEI)
+	}
+	if info.BlockNumber != nil {
+		fmt.Printf("Gas used:        %d (%.2f%%)\n", info.GasUsed, float64(info.GasUsed)*100/float64(info.GasLimit))
+		fmt.Printf("Effective price: %s %s\n", info.EffectiveGasPrice.String(), UnitWEI)
+		fmt.Printf("Fee:             %s %s\n", info.Fee, UnitETH)
+	}
+	if len(info.Data) > 0 {
+		fmt.Println("Data:           ", info.Data.String())
+	}
+	if info.Call != nil {
+		fmt.Println("Method:         ", info.Call.Method)
+		showDecodedArgs("  ", info.Call.Args)
+	}
+
+	if len(info.Logs) > 0 {
+		fmt.Println("Logs:")
+	}
+	for i, log := range info.Logs {
+		fmt.Printf("  [%d] %s\n", i, withNew(log.Address, log.NewAddress))
+		if log.Event != "" {
+			fmt.Println("      Event:", log.Event)
+			showDecodedArgs("        ", log.Args)
+			continue
+		}
+		for j, topic := range log.Topics {
+			fmt.Printf("      Topic%d: %s\n", j, topic.String())
+		}
+		fmt.Println("      Data:  ", log.Data.String())
+	}
+}
+
+func showDecodedArgs(indent string, args []DecodedArg) {
+	for _, arg := range args {
+		value, ok := arg.Value.(string)
+		if !ok {
+			b, err := json.Marshal(arg.Value)
+			if err != nil {
+				value = fmt.Sprint(arg.Value)
+			} else {
+				value = string(b)
+			}
+		}
+		fmt.Printf("%s%s %s: %s\n", indent, arg.Type, arg.Name, value)
+	}
+}
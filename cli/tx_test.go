@@ -1,11 +1,103 @@
 package cli
 
-import "testing"
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/newtonproject/newcommander/internal/rpctest"
+)
+
+// testSignedTx is the tx of the tx.json fixture, the signature differs every
+// time the tx is signed
+const testSignedTx = "f8698001825208947cbdfe7371f56a8f996d9eba7c66aeddb3f221f3880de0b6b3a764000080820802a075eeb48c116a47dcb17ff24d14f3e19bebcf2178e72441355fe1173c8775d0e0a0206bf62899b724667d8e028dddd5e789b5229e3473fde1092480819a5a829b7b"
 
 func TestSign(t *testing.T) {
+	walletPath := testWallet(t)
+	s := rpctest.NewServerFile(t, filepath.Join("testdata", "tx.json"))
+	golden, err := filepath.Abs(filepath.Join("testdata", "sign.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// sign and submit the files in the temp dir
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	tx := `{"from":"0xB186E935537A49FdfD394ab0B0110c5479AB51D2","to":"0x7cbdfE7371f56A8f996d9EBa7c66AEddB3f221f3",
+		"value":"1","unit":"NEW","nonce":0,"gasPrice":1,"gas":21000,"networkID":1007}`
+	if err := ioutil.WriteFile("tx", []byte(tx), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile("fixture.sign", []byte(testSignedTx), 0600); err != nil {
+		t.Fatal(err)
+	}
+
 	cli := NewCLI()
+	var out bytes.Buffer
+	for _, command := range []string{
+		"sign nothing",
+		"submit fixture.sign",
+	} {
+		fmt.Fprintf(&out, "$ newcommander %s\n", command)
+		out.WriteString(cli.TestCommand(fmt.Sprintf("%s -i %s -w %s", command, s.URL(), walletPath)))
+	}
+	rpctest.Golden(t, golden, out.Bytes())
+
+	cli.TestCommand(fmt.Sprintf("sign tx -w %s", walletPath))
+	signed, err := readLineFromFile("tx.sign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signTx types.Transaction
+	if err := rlp.DecodeBytes(common.FromHex(signed), &signTx); err != nil {
+		t.Fatal(err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(DefaultChainID), &signTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want types.Transaction
+	if err := rlp.DecodeBytes(common.FromHex(testSignedTx), &want); err != nil {
+		t.Fatal(err)
+	}
+	if from != common.HexToAddress("0xB186E935537A49FdfD394ab0B0110c5479AB51D2") || signTx.Nonce() != want.Nonce() ||
+		*signTx.To() != *want.To() || signTx.Value().Cmp(want.Value()) != 0 || signTx.Gas() != want.Gas() {
+		t.Errorf("signed tx from %s %s, want %s", from.String(), signed, testSignedTx)
+	}
+}
+
+func TestTxShow(t *testing.T) {
+	testGolden(t, "tx.json", "tx_show.golden",
+		"tx show 0x2e02e0c05aaf05fd4ac4a7b74d8903d0076c18a46c1a57ca924a32a235c8d955",
+		"tx show 0x2e02e0c05aaf05fd4ac4a7b74d8903d0076c18a46c1a57ca924a32a235c8d955 --json",
+	)
+}
 
-	cli.TestCommand("build")
-	cli.TestCommand("sign tx")
-	cli.TestCommand("submit tx.sign")
+func TestEffectiveGasPrice(t *testing.T) {
+	tx := types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(10)})
+	for _, tt := range []struct {
+		baseFee *big.Int
+		want    int64
+	}{{nil, 10}, {big.NewInt(5), 7}, {big.NewInt(9), 10}} {
+		if got := effectiveGasPrice(tx, tt.baseFee); got.Int64() != tt.want {
+			t.Errorf("effectiveGasPrice(%v) = %v, want %d", tt.baseFee, got, tt.want)
+		}
+	}
+	legacy := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(3)})
+	if got := effectiveGasPrice(legacy, big.NewInt(1)); got.Int64() != 3 {
+		t.Errorf("legacy effectiveGasPrice = %v, want 3", got)
+	}
 }
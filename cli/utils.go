@@ -146,10 +146,6 @@ func getWeiAmountTextByUnit(amount *big.Int, unit string) string {
 	return errIllegalUnit.Error()
 }
 
-func showTransactionReceipt(url, txStr string) {
-	sendJSONPostAndShow(url, "eth_getTransactionReceipt", txStr)
-}
-
 func sendJSONPostAndShow(url, method string, args ...interface{}) {
 	ctx := context.Background()
 	client, err := rpc.DialContext(ctx, url)
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultConfirmations = 1
+	defaultWaitTimeout   = 3 * time.Minute
+	waitPollInterval     = time.Second
+)
+
+var (
+	errWaitTimeout = errors.New("timeout waiting for transaction")
+	errTxReplaced  = errors.New("transaction nonce consumed by a different transaction")
+)
+
+// waitOptions controls how long and how deep waitTx waits for a transaction.
+type waitOptions struct {
+	Confirmations uint64
+	Timeout       time.Duration // zero to wait forever
+	PollInterval  time.Duration
+}
+
+func addWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64("confirmations", defaultConfirmations, "the number of blocks the transaction must be buried in before it is treated as final")
+	cmd.Flags().Duration("timeout", defaultWaitTimeout, "the max time to wait for the transaction, 0 to wait forever")
+}
+
+func getWaitOptions(cmd *cobra.Command) (*waitOptions, error) {
+	confirmations, err := cmd.Flags().GetUint64("confirmations")
+	if err != nil {
+		return nil, err
+	}
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return nil, err
+	}
+	if timeout < 0 {
+		return nil, errors.New("timeout is less than 0")
+	}
+
+	return &waitOptions{
+		Confirmations: confirmations,
+		Timeout:       timeout,
+		PollInterval:  waitPollInterval,
+	}, nil
+}
+
+// waitTx waits until the transaction is included and buried under the
+// requested number of confirmations. It follows the receipt across chain
+// reorganisations and returns errTxReplaced if the sender nonce was used
+// by another transaction.
+func waitTx(ctx context.Context, client *ethclient.Client, tx *types.Transaction, opts *waitOptions) (*types.Receipt, error) {
+	if opts == nil {
+		opts = &waitOptions{Confirmations: defaultConfirmations, Timeout: defaultWaitTimeout}
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = waitPollInterval
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, err
+	}
+
+	queryTicker := time.NewTicker(opts.PollInterval)
+	defer queryTicker.Stop()
+
+	var (
+		minedBlock common.Hash
+		confirmed  uint64
+	)
+	for {
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		if err == nil && receipt != nil {
+			if minedBlock != (common.Hash{}) && minedBlock != receipt.BlockHash {
+				fmt.Printf("Reorg detected: transaction moved from block %s to block %s\n",
+					minedBlock.String(), receipt.BlockHash.String())
+				confirmed = 0
+			}
+			minedBlock = receipt.BlockHash
+
+			n, err := txConfirmations(ctx, client, receipt)
+			if err == nil {
+				if n == 0 {
+					fmt.Printf("Reorg detected: block %s is no longer canonical\n", receipt.BlockHash.String())
+					minedBlock = common.Hash{}
+					confirmed = 0
+				} else if n != confirmed {
+					confirmed = n
+					if confirmed >= opts.Confirmations {
+						return receipt, nil
+					}
+					fmt.Printf("Transaction mined in block %s, confirmations %d/%d\n",
+						receipt.BlockNumber.String(), confirmed, opts.Confirmations)
+				}
+			}
+		} else if err == nil || errors.Is(err, ethereum.NotFound) {
+			if minedBlock != (common.Hash{}) {
+				fmt.Printf("Reorg detected: transaction dropped from block %s\n", minedBlock.String())
+				minedBlock = common.Hash{}
+				confirmed = 0
+			}
+			if err := checkReplaced(ctx, client, tx, from); err != nil {
+				return nil, err
+			}
+		}
+
+		// Wait for the next round.
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%w %s after %v", errWaitTimeout, tx.Hash().String(), opts.Timeout)
+			}
+			return nil, ctx.Err()
+		case <-queryTicker.C:
+		}
+	}
+}
+
+// txConfirmations returns how many blocks, including its own, bury the
+// receipt in the canonical chain. It returns zero when the receipt block
+// is not canonical any more.
+func txConfirmations(ctx context.Context, client *ethclient.Client, receipt *types.Receipt) (uint64, error) {
+	header, err := client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return 0, err
+	}
+	if header.Hash() != receipt.BlockHash {
+		return 0, nil
+	}
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	number := receipt.BlockNumber.Uint64()
+	if head < number {
+		return 1, nil
+	}
+	return head - number + 1, nil
+}
+
+// checkReplaced reports errTxReplaced if the nonce of tx has been consumed
+// in the latest block while tx itself is neither mined nor pending.
+func checkReplaced(ctx context.Context, client *ethclient.Client, tx *types.Transaction, from common.Address) error {
+	nonce, err := client.NonceAt(ctx, from, nil)
+	if err != nil || nonce <= tx.Nonce() {
+		return nil
+	}
+	if _, _, err := client.TransactionByHash(ctx, tx.Hash()); err == nil {
+		// still known to the node, the receipt may just lag behind
+		return nil
+	}
+	if _, err := client.TransactionReceipt(ctx, tx.Hash()); err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("%w: nonce %d of %s, tx %s", errTxReplaced, tx.Nonce(), from.String(), tx.Hash().String())
+}
+
+func showWaitResult(receipt *types.Receipt) {
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		fmt.Printf("Succeed mined txID %s in block %s.\n", receipt.TxHash.String(), receipt.BlockNumber.String())
+	} else {
+		fmt.Printf("Succeed mined txID %s in block %s but status failed.\n", receipt.TxHash.String(), receipt.BlockNumber.String())
+	}
+}
+
+// receiptFee returns the fee paid by the mined transaction, at the effective
+// gas price with the base fee of its block.
+func receiptFee(ctx context.Context, client *ethclient.Client, receipt *types.Receipt, tx *types.Transaction) (*big.Int, error) {
+	var baseFee *big.Int
+	if tx.Type() == types.DynamicFeeTxType {
+		header, err := client.HeaderByHash(ctx, receipt.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+		baseFee = header.BaseFee
+	}
+	return big.NewInt(0).Mul(effectiveGasPrice(tx, baseFee), big.NewInt(0).SetUint64(receipt.GasUsed)), nil
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/newtonproject/newcommander/internal/rpctest"
+)
+
+// testCall returns the call of the method with the result and the first
+// params
+func testCall(t *testing.T, method string, result interface{}, params ...interface{}) *rpctest.Call {
+	t.Helper()
+	call := &rpctest.Call{Method: method}
+	for _, param := range params {
+		b, err := json.Marshal(param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		call.Params = append(call.Params, b)
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call.Result = b
+	return call
+}
+
+func testWaitHeader(number int64, extra string, baseFee *big.Int) *types.Header {
+	return &types.Header{
+		Number:     big.NewInt(number),
+		Difficulty: big.NewInt(1),
+		GasLimit:   8000000,
+		Extra:      []byte(extra),
+		BaseFee:    baseFee,
+	}
+}
+
+func testWaitReceipt(tx *types.Transaction, header *types.Header) *types.Receipt {
+	return &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		GasUsed:           21000,
+		Logs:              []*types.Log{},
+		TxHash:            tx.Hash(),
+		BlockHash:         header.Hash(),
+		BlockNumber:       header.Number,
+	}
+}
+
+func testWaitTx(t *testing.T, txdata types.TxData) (*types.Transaction, common.Address) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(DefaultChainID), txdata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func TestWaitTx(t *testing.T) {
+	tx, from := testWaitTx(t, &types.LegacyTx{Nonce: 5, GasPrice: big.NewInt(1), Gas: 21000, To: &common.Address{}})
+	blockA := testWaitHeader(10, "a", nil)
+	blockB := testWaitHeader(10, "b", nil)
+	receiptA, receiptB := testWaitReceipt(tx, blockA), testWaitReceipt(tx, blockB)
+
+	tests := []struct {
+		name  string
+		calls []*rpctest.Call
+		want  common.Hash
+		err   error
+	}{
+		{
+			// the receipt moves to the block of the same number
+			name: "moved",
+			calls: []*rpctest.Call{
+				testCall(t, "eth_getTransactionReceipt", receiptA, tx.Hash()),
+				testCall(t, "eth_getTransactionReceipt", receiptB, tx.Hash()),
+				testCall(t, "eth_getBlockByNumber", blockA, "0xa"),
+				testCall(t, "eth_getBlockByNumber", blockB, "0xa"),
+				testCall(t, "eth_blockNumber", "0xa"),
+				testCall(t, "eth_blockNumber", "0xb"),
+			},
+			want: blockB.Hash(),
+		},
+		{
+			// the node still returns the receipt of the block not canonical
+			name: "not canonical",
+			calls: []*rpctest.Call{
+				testCall(t, "eth_getTransactionReceipt", receiptA, tx.Hash()),
+				testCall(t, "eth_getTransactionReceipt", receiptB, tx.Hash()),
+				testCall(t, "eth_getBlockByNumber", blockB, "0xa"),
+				testCall(t, "eth_blockNumber", "0xb"),
+			},
+			want: blockB.Hash(),
+		},
+		{
+			// the receipt is dropped and the nonce is used by another tx
+			name: "replaced",
+			calls: []*rpctest.Call{
+				testCall(t, "eth_getTransactionReceipt", receiptA, tx.Hash()),
+				testCall(t, "eth_getTransactionReceipt", nil, tx.Hash()),
+				testCall(t, "eth_getBlockByNumber", blockA, "0xa"),
+				testCall(t, "eth_blockNumber", "0xa"),
+				testCall(t, "eth_getTransactionCount", "0x6", from),
+				testCall(t, "eth_getTransactionByHash", nil, tx.Hash()),
+			},
+			err: errTxReplaced,
+		},
+		{
+			// the nonce is used but the tx is still pending
+			name: "pending",
+			calls: []*rpctest.Call{
+				testCall(t, "eth_getTransactionReceipt", nil, tx.Hash()),
+				testCall(t, "eth_getTransactionCount", "0x6", from),
+				testCall(t, "eth_getTransactionByHash", tx, tx.Hash()),
+			},
+			err: errWaitTimeout,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, err := ethclient.Dial(rpctest.NewServer(t, test.calls).URL())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			opts := &waitOptions{Confirmations: 2, Timeout: 200 * time.Millisecond, PollInterval: time.Millisecond}
+			receipt, err := waitTx(context.Background(), client, tx, opts)
+			if test.err != nil {
+				if !errors.Is(err, test.err) {
+					t.Fatalf("error %v, want %v", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if receipt.BlockHash != test.want {
+				t.Errorf("receipt in block %s, want %s", receipt.BlockHash.String(), test.want.String())
+			}
+		})
+	}
+}
+
+func TestReceiptFee(t *testing.T) {
+	tx, _ := testWaitTx(t, &types.DynamicFeeTx{ChainID: DefaultChainID, GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(10), Gas: 21000, To: &common.Address{}})
+	header := testWaitHeader(10, "", big.NewInt(5))
+	receipt := testWaitReceipt(tx, header)
+
+	client, err := ethclient.Dial(rpctest.NewServer(t, []*rpctest.Call{
+		testCall(t, "eth_getBlockByHash", header, header.Hash()),
+	}).URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	fee, err := receiptFee(context.Background(), client, receipt, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// base fee 5 + tip 2 instead of the fee cap 10
+	if want := big.NewInt(7 * 21000); fee.Cmp(want) != 0 {
+		t.Errorf("fee %v, want %v", fee, (*hexutil.Big)(want))
+	}
+}
@@ -0,0 +1,422 @@
+// Package index stores the txs, receipts and internal transfers of the
+// watched addresses in a local database.
+package index
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/newtonproject/newcommander/tracer"
+)
+
+/*
+Database layout, numbers are big endian:
+
+	"meta"                                   -> json Meta
+	"h" + number(8)                          -> block hash of the synced block
+	"t" + number(8) + txIndex(4)             -> json Tx
+	"a" + address(20) + number(8) + txIndex(4) -> nil, the txs of the watched address
+*/
+
+var (
+	metaKey         = []byte("meta")
+	blockHashPrefix = []byte("h")
+	txPrefix        = []byte("t")
+	addressPrefix   = []byte("a")
+)
+
+// ErrNotSynced is returned if the index has no synced block.
+var ErrNotSynced = errors.New("index not synced")
+
+// Meta is the sync state of the index
+type Meta struct {
+	Addresses []common.Address `json:"addresses"`
+	Internal  bool             `json:"internal"`
+	From      uint64           `json:"from"`
+	Head      uint64           `json:"head"`
+	HeadHash  common.Hash      `json:"headHash"`
+}
+
+// Tx is an indexed tx of the watched addresses with its receipt and the
+// calls traced from it.
+type Tx struct {
+	BlockNumber uint64             `json:"blockNumber"`
+	BlockHash   common.Hash        `json:"blockHash"`
+	Timestamp   uint64             `json:"timestamp"`
+	Index       uint               `json:"transactionIndex"`
+	From        common.Address     `json:"from"`
+	BaseFee     *big.Int           `json:"baseFee,omitempty"`
+	Tx          *types.Transaction `json:"tx"`
+	Receipt     *types.Receipt     `json:"receipt"`
+	Calls       []*tracer.Tx       `json:"calls,omitempty"`
+}
+
+// Addresses returns the addresses of the tx and its calls, the calls
+// transferring no value are skipped except the first which is the tx itself.
+func (tx *Tx) Addresses() []common.Address {
+	seen := make(map[common.Address]bool)
+	var addresses []common.Address
+	add := func(address *common.Address) {
+		if address != nil && !seen[*address] {
+			seen[*address] = true
+			addresses = append(addresses, *address)
+		}
+	}
+	add(&tx.From)
+	add(tx.Tx.To())
+	if tx.Receipt != nil && tx.Tx.To() == nil {
+		add(&tx.Receipt.ContractAddress)
+	}
+	for i, call := range tx.Calls {
+		if i > 0 && (!call.IsTransfer() || tracer.IsReverted(tx.Calls, i)) {
+			continue
+		}
+		add(&call.From)
+		add(call.To)
+	}
+	return addresses
+}
+
+// IsCounterparty reports if the counterparty is the other side of a watched
+// address in the transfer from from to to.
+func IsCounterparty(from common.Address, to *common.Address, watched map[common.Address]bool, counterparty common.Address) bool {
+	if to == nil {
+		return false
+	}
+	return (watched[from] && *to == counterparty) || (watched[*to] && from == counterparty)
+}
+
+// HasCounterparty reports if the counterparty is the other side of a watched
+// address in the tx or its internal transfers.
+func (tx *Tx) HasCounterparty(watched map[common.Address]bool, counterparty common.Address) bool {
+	to := tx.Tx.To()
+	if to == nil && tx.Receipt != nil {
+		to = &tx.Receipt.ContractAddress
+	}
+	if IsCounterparty(tx.From, to, watched, counterparty) {
+		return true
+	}
+	for i, call := range tx.Calls {
+		if i > 0 && (!call.IsTransfer() || tracer.IsReverted(tx.Calls, i)) {
+			continue
+		}
+		if IsCounterparty(call.From, call.To, watched, counterparty) {
+			return true
+		}
+	}
+	return false
+}
+
+// Block is the indexed txs of a synced block
+type Block struct {
+	Number     uint64
+	Hash       common.Hash
+	ParentHash common.Hash
+	Txs        []*Tx
+}
+
+// DB is the index database.
+type DB struct {
+	db ethdb.KeyValueStore
+}
+
+// Open opens the index database in the directory.
+func Open(path string) (*DB, error) {
+	db, err := leveldb.New(path, 16, 16, "", false)
+	if err != nil {
+		return nil, err
+	}
+	return NewDB(db), nil
+}
+
+// OpenReadOnly opens the index database in the directory to read, it
+// returns ErrNotSynced without creating anything if the directory does not
+// exist. The database can not be opened while a sync holds its lock.
+func OpenReadOnly(path string) (*DB, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, ErrNotSynced
+	} else if err != nil {
+		return nil, err
+	}
+	db, err := leveldb.New(path, 16, 16, "", true)
+	if err != nil {
+		return nil, err
+	}
+	return NewDB(db), nil
+}
+
+// NewDB returns the index on the key value store.
+func NewDB(db ethdb.KeyValueStore) *DB {
+	return &DB{db: db}
+}
+
+// Close closes the database.
+func (db *DB) Close() error {
+	return db.db.Close()
+}
+
+func encodeNumber(number uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, number)
+	return b
+}
+
+func blockHashKey(number uint64) []byte {
+	return append(append([]byte{}, blockHashPrefix...), encodeNumber(number)...)
+}
+
+func txKey(number uint64, index uint) []byte {
+	key := append(append([]byte{}, txPrefix...), encodeNumber(number)...)
+	return append(key, byte(index>>24), byte(index>>16), byte(index>>8), byte(index))
+}
+
+func addressKey(address common.Address, number uint64, index uint) []byte {
+	key := append(append([]byte{}, addressPrefix...), address.Bytes()...)
+	return append(key, txKey(number, index)[len(txPrefix):]...)
+}
+
+// Meta returns the sync state, nil if the index is empty.
+func (db *DB) Meta() (*Meta, error) {
+	if ok, err := db.db.Has(metaKey); err != nil || !ok {
+		return nil, err
+	}
+	b, err := db.db.Get(metaKey)
+	if err != nil {
+		return nil, err
+	}
+	var meta Meta
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+func putMeta(w ethdb.KeyValueWriter, meta *Meta) error {
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return w.Put(metaKey, b)
+}
+
+// Init sets the watched addresses and the first block of an empty index.
+func (db *DB) Init(addresses []common.Address, internal bool, from uint64) error {
+	meta := &Meta{
+		Addresses: addresses,
+		Internal:  internal,
+		From:      from,
+	}
+	if from > 0 {
+		meta.Head = from - 1
+	}
+	return putMeta(db.db, meta)
+}
+
+// BlockHash returns the hash of the synced block.
+func (db *DB) BlockHash(number uint64) (common.Hash, bool) {
+	b, err := db.db.Get(blockHashKey(number))
+	if err != nil {
+		return common.Hash{}, false
+	}
+	return common.BytesToHash(b), true
+}
+
+// WriteBlocks writes the synced blocks in order and moves the head to the
+// last one atomically.
+func (db *DB) WriteBlocks(blocks []*Block) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	meta, err := db.Meta()
+	if err != nil {
+		return err
+	} else if meta == nil {
+		return ErrNotSynced
+	}
+	watched := make(map[common.Address]bool)
+	for _, address := range meta.Addresses {
+		watched[address] = true
+	}
+
+	batch := db.db.NewBatch()
+	for _, block := range blocks {
+		if err := batch.Put(blockHashKey(block.Number), block.Hash.Bytes()); err != nil {
+			return err
+		}
+		for _, tx := range block.Txs {
+			b, err := json.Marshal(tx)
+			if err != nil {
+				return err
+			}
+			if err := batch.Put(txKey(tx.BlockNumber, tx.Index), b); err != nil {
+				return err
+			}
+			for _, address := range tx.Addresses() {
+				if watched[address] {
+					if err := batch.Put(addressKey(address, tx.BlockNumber, tx.Index), nil); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	last := blocks[len(blocks)-1]
+	meta.Head, meta.HeadHash = last.Number, last.Hash
+	if err := putMeta(batch, meta); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// Rollback deletes the blocks above the number and moves the head back.
+func (db *DB) Rollback(number uint64) error {
+	meta, err := db.Meta()
+	if err != nil {
+		return err
+	} else if meta == nil {
+		return ErrNotSynced
+	}
+
+	batch := db.db.NewBatch()
+	for n := number + 1; n <= meta.Head; n++ {
+		if err := batch.Delete(blockHashKey(n)); err != nil {
+			return err
+		}
+		txs, err := db.txs(n, n)
+		if err != nil {
+			return err
+		}
+		for _, tx := range txs {
+			if err := batch.Delete(txKey(tx.BlockNumber, tx.Index)); err != nil {
+				return err
+			}
+			for _, address := range tx.Addresses() {
+				if err := batch.Delete(addressKey(address, tx.BlockNumber, tx.Index)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	meta.Head = number
+	meta.HeadHash, _ = db.BlockHash(number)
+	if err := putMeta(batch, meta); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// txs returns the txs in the block range.
+func (db *DB) txs(from, to uint64) ([]*Tx, error) {
+	it := db.db.NewIterator(txPrefix, encodeNumber(from))
+	defer it.Release()
+
+	var txs []*Tx
+	for it.Next() {
+		key := it.Key()[len(txPrefix):]
+		if binary.BigEndian.Uint64(key[:8]) > to {
+			break
+		}
+		var tx Tx
+		if err := json.Unmarshal(it.Value(), &tx); err != nil {
+			return nil, err
+		}
+		txs = append(txs, &tx)
+	}
+	return txs, it.Error()
+}
+
+// Filter selects the indexed txs, the zero value selects all.
+type Filter struct {
+	Addresses    []common.Address // the txs of any of the addresses
+	FromBlock    uint64
+	ToBlock      uint64          // zero for the head
+	Counterparty *common.Address // the other side of the addresses in the txs
+}
+
+// Query returns the indexed txs matching the filter in the block order.
+func (db *DB) Query(filter *Filter) ([]*Tx, error) {
+	to := filter.ToBlock
+	if to == 0 {
+		to = ^uint64(0)
+	}
+
+	var txs []*Tx
+	if len(filter.Addresses) == 0 {
+		all, err := db.txs(filter.FromBlock, to)
+		if err != nil {
+			return nil, err
+		}
+		txs = all
+	} else {
+		seen := make(map[string]bool)
+		for _, address := range filter.Addresses {
+			prefix := append(append([]byte{}, addressPrefix...), address.Bytes()...)
+			it := db.db.NewIterator(prefix, encodeNumber(filter.FromBlock))
+			for it.Next() {
+				key := it.Key()[len(prefix):]
+				if binary.BigEndian.Uint64(key[:8]) > to {
+					break
+				}
+				if seen[string(key)] {
+					continue
+				}
+				seen[string(key)] = true
+				b, err := db.db.Get(append(append([]byte{}, txPrefix...), key...))
+				if err != nil {
+					it.Release()
+					return nil, err
+				}
+				var tx Tx
+				if err := json.Unmarshal(b, &tx); err != nil {
+					it.Release()
+					return nil, err
+				}
+				txs = append(txs, &tx)
+			}
+			err := it.Error()
+			it.Release()
+			if err != nil {
+				return nil, err
+			}
+		}
+		sort.Slice(txs, func(i, j int) bool {
+			if txs[i].BlockNumber != txs[j].BlockNumber {
+				return txs[i].BlockNumber < txs[j].BlockNumber
+			}
+			return txs[i].Index < txs[j].Index
+		})
+	}
+
+	if filter.Counterparty == nil {
+		return txs, nil
+	}
+	addresses := filter.Addresses
+	if len(addresses) == 0 {
+		meta, err := db.Meta()
+		if err != nil {
+			return nil, err
+		}
+		if meta != nil {
+			addresses = meta.Addresses
+		}
+	}
+	watched := make(map[common.Address]bool)
+	for _, address := range addresses {
+		watched[address] = true
+	}
+	filtered := txs[:0]
+	for _, tx := range txs {
+		if tx.HasCounterparty(watched, *filter.Counterparty) {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered, nil
+}
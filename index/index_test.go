@@ -0,0 +1,166 @@
+package index
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/newtonproject/newcommander/tracer"
+)
+
+var (
+	alice   = common.HexToAddress("0x97549e368acafdcae786bb93d98379f1d1561a29")
+	bob     = common.HexToAddress("0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54")
+	carol   = common.HexToAddress("0x82a3a88bc9d6a70c4f3c66534566892eae0cad81")
+	token   = common.HexToAddress("0x415ba9d241de28eabeac9fa33379d5f923d76956")
+	oneCoin = big.NewInt(1e18)
+)
+
+func testTx(number uint64, index uint, from, to common.Address, calls ...*tracer.Tx) *Tx {
+	return &Tx{
+		BlockNumber: number,
+		BlockHash:   common.BigToHash(new(big.Int).SetUint64(number)),
+		Index:       index,
+		From:        from,
+		Tx:          types.NewTransaction(uint64(index), to, oneCoin, 21000, big.NewInt(1), nil),
+		Receipt:     &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 21000, Logs: []*types.Log{}},
+		Calls:       calls,
+	}
+}
+
+func testBlock(number uint64, txs ...*Tx) *Block {
+	return &Block{
+		Number:     number,
+		Hash:       common.BigToHash(new(big.Int).SetUint64(number)),
+		ParentHash: common.BigToHash(new(big.Int).SetUint64(number - 1)),
+		Txs:        txs,
+	}
+}
+
+func TestIndex(t *testing.T) {
+	db := NewDB(memorydb.New())
+	if meta, err := db.Meta(); err != nil || meta != nil {
+		t.Fatalf("meta of empty index %v, %v", meta, err)
+	}
+	if err := db.WriteBlocks([]*Block{testBlock(10)}); err != ErrNotSynced {
+		t.Fatalf("write to empty index error %v, want %v", err, ErrNotSynced)
+	}
+	if err := db.Init([]common.Address{alice}, true, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	internal := testTx(11, 0, carol, token,
+		&tracer.Tx{From: carol, To: &token, Value: new(big.Int)},
+		&tracer.Tx{From: token, To: &alice, Value: oneCoin})
+	blocks := []*Block{
+		testBlock(10, testTx(10, 1, alice, bob)),
+		testBlock(11, internal, testTx(11, 2, bob, alice)),
+		testBlock(12, testTx(12, 0, alice, carol)),
+	}
+	if err := db.WriteBlocks(blocks); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := db.Meta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Head != 12 || meta.HeadHash != blocks[2].Hash {
+		t.Fatalf("head %d %x, want 12 %x", meta.Head, meta.HeadHash, blocks[2].Hash)
+	}
+
+	tests := []struct {
+		filter *Filter
+		want   []uint64 // block number * 10 + tx index
+	}{
+		{&Filter{}, []uint64{101, 110, 112, 120}},
+		{&Filter{Addresses: []common.Address{alice}}, []uint64{101, 110, 112, 120}},
+		{&Filter{Addresses: []common.Address{alice}, FromBlock: 11, ToBlock: 11}, []uint64{110, 112}},
+		{&Filter{Addresses: []common.Address{alice}, Counterparty: &bob}, []uint64{101, 112}},
+		{&Filter{Addresses: []common.Address{alice}, Counterparty: &token}, []uint64{110}},
+		{&Filter{Counterparty: &bob}, []uint64{101, 112}},
+		// the counterparty is the other side of the watched address
+		{&Filter{Addresses: []common.Address{alice}, Counterparty: &alice}, nil},
+		{&Filter{Addresses: []common.Address{alice}, Counterparty: &carol}, []uint64{120}},
+		// only the watched addresses are indexed
+		{&Filter{Addresses: []common.Address{bob}}, nil},
+	}
+	for i, tt := range tests {
+		txs, err := db.Query(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []uint64
+		for _, tx := range txs {
+			got = append(got, tx.BlockNumber*10+uint64(tx.Index))
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("test %d: got %v, want %v", i, got, tt.want)
+			continue
+		}
+		for j := range got {
+			if got[j] != tt.want[j] {
+				t.Errorf("test %d: got %v, want %v", i, got, tt.want)
+				break
+			}
+		}
+	}
+
+	txs, err := db.Query(&Filter{FromBlock: 11, ToBlock: 11})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs[0].Calls) != 2 || txs[0].Calls[1].Value.Cmp(oneCoin) != 0 || txs[0].Tx.Value().Cmp(oneCoin) != 0 {
+		t.Errorf("decoded tx %+v mismatch", txs[0])
+	}
+
+	if err := db.Rollback(10); err != nil {
+		t.Fatal(err)
+	}
+	if meta, _ = db.Meta(); meta.Head != 10 || meta.HeadHash != blocks[0].Hash {
+		t.Errorf("head after rollback %d %x, want 10 %x", meta.Head, meta.HeadHash, blocks[0].Hash)
+	}
+	if _, ok := db.BlockHash(11); ok {
+		t.Error("block 11 not rolled back")
+	}
+	if txs, _ := db.Query(&Filter{Addresses: []common.Address{alice}}); len(txs) != 1 {
+		t.Errorf("%d txs after rollback, want 1", len(txs))
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index")
+	if _, err := OpenReadOnly(path); err != ErrNotSynced {
+		t.Fatalf("open missing index error %v, want %v", err, ErrNotSynced)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("open missing index created %s: %v", path, err)
+	}
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Init([]common.Address{alice}, false, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = OpenReadOnly(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	meta, err := db.Meta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta == nil || len(meta.Addresses) != 1 || meta.Addresses[0] != alice || meta.From != 10 {
+		t.Fatalf("unexpected meta %+v", meta)
+	}
+}
@@ -0,0 +1,243 @@
+// Package rpctest serves the recorded JSON-RPC responses for the tests, so
+// they run without any node.
+//
+// The fixtures are JSON arrays of the calls:
+//
+//	[
+//	  {"method": "eth_getBalance", "params": ["0x97549e368acafdcae786bb93d98379f1d1561a29"], "result": "0xde0b6b3a7640000"},
+//	  {"method": "eth_gasPrice", "error": {"code": -32000, "message": "not available"}}
+//	]
+//
+// A call matches the request of the same method whose first params equal
+// the params of the call, the call without params matches any params. The
+// calls are matched in order so the same request can get the different
+// responses, the last matched call is kept replaying.
+//
+// Set RPCTEST_RECORD to the URL of a node to record the fixtures from it
+// instead, the fixture file is rewritten when the test finishes.
+//
+// The fixtures in the testdata of the cli and tracer packages are synthetic,
+// written for the tests from the headers, txs and receipts built and signed
+// with the test keys, not recorded from a node. Record them again with
+// RPCTEST_RECORD to test against the responses of a real node, the goldens
+// are then rewritten with -update.
+package rpctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+// RecordEnv is the environment variable of the node URL to record from.
+const RecordEnv = "RPCTEST_RECORD"
+
+// Error is the JSON-RPC error of the call
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Call is a recorded JSON-RPC call
+type Call struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage   `json:"result,omitempty"`
+	Error  *Error            `json:"error,omitempty"`
+}
+
+// matches reports if the call is recorded for the request.
+func (c *Call) matches(method string, params []json.RawMessage) bool {
+	if c.Method != method || len(c.Params) > len(params) {
+		return false
+	}
+	for i := range c.Params {
+		if !jsonEqual(c.Params[i], params[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+	ca, _ := json.Marshal(va)
+	cb, _ := json.Marshal(vb)
+	return bytes.Equal(ca, cb)
+}
+
+type request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Server replays the recorded calls
+type Server struct {
+	t      testing.TB
+	server *httptest.Server
+
+	mu      sync.Mutex
+	calls   []*Call
+	used    map[*Call]bool
+	record  string
+	records []*Call
+}
+
+// NewServer returns the server replaying the calls, it is closed when the
+// test finishes.
+func NewServer(t testing.TB, calls []*Call) *Server {
+	s := &Server{t: t, calls: calls, used: make(map[*Call]bool)}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// NewServerFile returns the server replaying the calls of the fixture file,
+// or recording them to the file if RecordEnv is set.
+func NewServerFile(t testing.TB, file string) *Server {
+	t.Helper()
+	if node := os.Getenv(RecordEnv); node != "" {
+		s := NewServer(t, nil)
+		s.record = node
+		t.Cleanup(func() {
+			b, err := json.MarshalIndent(s.records, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(file, append(b, '\n'), 0644); err != nil {
+				t.Fatal(err)
+			}
+		})
+		return s
+	}
+	return NewServer(t, Load(t, file))
+}
+
+// Load reads the calls of the fixture file.
+func Load(t testing.TB, file string) []*Call {
+	t.Helper()
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls []*Call
+	if err := json.Unmarshal(b, &calls); err != nil {
+		t.Fatalf("fixture %s: %v", file, err)
+	}
+	return calls
+}
+
+// URL returns the JSON-RPC endpoint of the server.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var out interface{}
+	if batch := bytes.TrimSpace(body); len(batch) > 0 && batch[0] == '[' {
+		var reqs []*request
+		if err := json.Unmarshal(batch, &reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resps := make([]*response, 0, len(reqs))
+		for _, req := range reqs {
+			resps = append(resps, s.respond(req))
+		}
+		out = resps
+	} else {
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		out = s.respond(&req)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+func (s *Server) respond(req *request) *response {
+	resp := &response{Version: "2.0", ID: req.ID}
+	if s.record != "" {
+		call := s.forward(req)
+		resp.Result, resp.Error = call.Result, call.Error
+		return resp
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var last *Call
+	for _, call := range s.calls {
+		if !call.matches(req.Method, req.Params) {
+			continue
+		}
+		last = call
+		if !s.used[call] {
+			break
+		}
+	}
+	if last == nil {
+		params, _ := json.Marshal(req.Params)
+		s.t.Logf("rpctest: no recorded call of %s %s", req.Method, params)
+		resp.Error = &Error{Code: -32601, Message: fmt.Sprintf("the method %s does not exist/is not available", req.Method)}
+		return resp
+	}
+	s.used[last] = true
+	resp.Result, resp.Error = last.Result, last.Error
+	if resp.Result == nil && resp.Error == nil {
+		resp.Result = json.RawMessage("null")
+	}
+	return resp
+}
+
+// forward sends the request to the recorded node and keeps the call.
+func (s *Server) forward(req *request) *Call {
+	call := &Call{Method: req.Method, Params: req.Params}
+	body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": req.Method, "params": req.Params})
+	httpResp, err := http.Post(s.record, "application/json", bytes.NewReader(body))
+	if err != nil {
+		call.Error = &Error{Code: -32000, Message: err.Error()}
+		return call
+	}
+	defer httpResp.Body.Close()
+
+	var resp response
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		call.Error = &Error{Code: -32000, Message: err.Error()}
+		return call
+	}
+	call.Result, call.Error = resp.Result, resp.Error
+	if call.Result == nil && call.Error == nil {
+		call.Result = json.RawMessage("null")
+	}
+
+	s.mu.Lock()
+	s.records = append(s.records, call)
+	s.mu.Unlock()
+	return call
+}
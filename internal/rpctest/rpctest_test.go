@@ -0,0 +1,55 @@
+package rpctest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestServer(t *testing.T) {
+	s := NewServer(t, []*Call{
+		{Method: "eth_blockNumber", Result: json.RawMessage(`"0x1"`)},
+		{Method: "eth_blockNumber", Result: json.RawMessage(`"0x2"`)},
+		{Method: "eth_getBalance", Params: []json.RawMessage{json.RawMessage(`"0x01"`)}, Result: json.RawMessage(`"0x10"`)},
+		{Method: "eth_getBalance", Result: json.RawMessage(`"0x0"`)},
+		{Method: "eth_gasPrice", Error: &Error{Code: -32000, Message: "no gas price"}},
+	})
+	c, err := rpc.Dial(s.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	// the same request gets the calls in order, then the last one
+	for _, want := range []string{"0x1", "0x2", "0x2"} {
+		var number string
+		if err := c.CallContext(ctx, &number, "eth_blockNumber"); err != nil {
+			t.Fatal(err)
+		}
+		if number != want {
+			t.Errorf("block number %s, want %s", number, want)
+		}
+	}
+
+	batch := []rpc.BatchElem{
+		{Method: "eth_getBalance", Args: []interface{}{"0x01", "latest"}, Result: new(string)},
+		{Method: "eth_getBalance", Args: []interface{}{"0x02", "latest"}, Result: new(string)},
+		{Method: "eth_gasPrice", Result: new(string)},
+		{Method: "eth_chainId", Result: new(string)},
+	}
+	if err := c.BatchCallContext(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+	if *batch[0].Result.(*string) != "0x10" || *batch[1].Result.(*string) != "0x0" {
+		t.Errorf("balances %s %s, want 0x10 0x0", *batch[0].Result.(*string), *batch[1].Result.(*string))
+	}
+	if batch[2].Error == nil || batch[2].Error.Error() != "no gas price" {
+		t.Errorf("error %v, want the recorded error", batch[2].Error)
+	}
+	if batch[3].Error == nil {
+		t.Error("want error of the call not recorded")
+	}
+}
@@ -0,0 +1,281 @@
+package tracer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Backend is the tracing API of the node
+type Backend int
+
+const (
+	BackendAuto       Backend = iota // detect by the first trace
+	BackendJS                        // debug_traceTransaction with tracer.js
+	BackendCallTracer                // debug_traceTransaction with the built-in callTracer
+	BackendParity                    // trace_transaction
+)
+
+var backendNames = map[Backend]string{
+	BackendAuto:       "auto",
+	BackendJS:         "js",
+	BackendCallTracer: "callTracer",
+	BackendParity:     "parity",
+}
+
+func (b Backend) String() string {
+	if name, ok := backendNames[b]; ok {
+		return name
+	}
+	return fmt.Sprintf("Backend(%d)", int(b))
+}
+
+// ParseBackend parses the backend name.
+func ParseBackend(name string) (Backend, error) {
+	for b, n := range backendNames {
+		if strings.EqualFold(n, name) {
+			return b, nil
+		}
+	}
+	return BackendAuto, fmt.Errorf("unknown tracer backend %s", name)
+}
+
+// detectOrder is the order to try the backends in auto mode.
+var detectOrder = []Backend{BackendJS, BackendCallTracer, BackendParity}
+
+// Tracer traces the transactions with the backend of the node.
+type Tracer struct {
+	client *rpc.Client
+
+	mu      sync.Mutex
+	backend Backend
+}
+
+// New returns the tracer of the backend, BackendAuto detects the backend by
+// the first successful trace.
+func New(c *rpc.Client, backend Backend) *Tracer {
+	return &Tracer{client: c, backend: backend}
+}
+
+// Backend returns the backend in use, BackendAuto if not detected yet.
+func (t *Tracer) Backend() Backend {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.backend
+}
+
+// TraceTransaction traces the transaction and returns the call frames in
+// the order of execution.
+func (t *Tracer) TraceTransaction(ctx context.Context, txHash common.Hash, config *TraceConfig) ([]*Tx, error) {
+	backend := t.Backend()
+	if backend != BackendAuto {
+		return traceWithBackend(t.client, ctx, txHash, config, backend)
+	}
+
+	var firstErr error
+	for _, b := range detectOrder {
+		txs, err := traceWithBackend(t.client, ctx, txHash, config, b)
+		if err == nil {
+			t.mu.Lock()
+			t.backend = b
+			t.mu.Unlock()
+			return txs, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+func traceWithBackend(c *rpc.Client, ctx context.Context, txHash common.Hash, config *TraceConfig, backend Backend) ([]*Tx, error) {
+	ctx, cancel := callContext(ctx, config)
+	defer cancel()
+
+	switch backend {
+	case BackendJS:
+		return TraceTransaction(c, ctx, txHash, config)
+	case BackendCallTracer:
+		return TraceCallTracer(c, ctx, txHash, config)
+	case BackendParity:
+		return TraceParity(c, ctx, txHash)
+	}
+	return nil, fmt.Errorf("unsupported tracer backend %s", backend)
+}
+
+// callFrame is the nested call frame returned by callTracer
+type callFrame struct {
+	Type         string          `json:"type"`
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to"`
+	Value        *hexutil.Big    `json:"value"`
+	Gas          hexutil.Uint64  `json:"gas"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	Input        hexutil.Bytes   `json:"input"`
+	Output       hexutil.Bytes   `json:"output"`
+	Error        string          `json:"error"`
+	RevertReason string          `json:"revertReason"`
+	Calls        []*callFrame    `json:"calls"`
+}
+
+// flatten appends the frame and its sub calls in the order of execution.
+func (f *callFrame) flatten(txs []*Tx, traceAddress []int) []*Tx {
+	tx := &Tx{
+		Type:         strings.ToLower(f.Type),
+		From:         f.From,
+		To:           f.To,
+		Input:        f.Input,
+		Output:       f.Output,
+		Value:        (*big.Int)(f.Value),
+		Gas:          uint64(f.Gas),
+		GasUsed:      uint64(f.GasUsed),
+		Error:        f.Error,
+		RevertReason: f.RevertReason,
+		Depth:        len(traceAddress),
+		TraceAddress: traceAddress,
+	}
+	if tx.Value == nil {
+		tx.Value = new(big.Int)
+	}
+	if tx.RevertReason == "" {
+		tx.RevertReason = revertReason(tx.Error, tx.Output)
+	}
+	txs = append(txs, tx)
+
+	for i, call := range f.Calls {
+		address := make([]int, len(traceAddress), len(traceAddress)+1)
+		copy(address, traceAddress)
+		txs = call.flatten(txs, append(address, i))
+	}
+	return txs
+}
+
+// TraceCallTracer traces the transaction with the built-in callTracer and
+// flattens the nested frames.
+func TraceCallTracer(c *rpc.Client, ctx context.Context, txHash common.Hash, config *TraceConfig) ([]*Tx, error) {
+	cfg := TraceConfig{Tracer: "callTracer"}
+	if config != nil {
+		cfg.Timeout, cfg.Reexec = config.Timeout, config.Reexec
+	}
+
+	var raw json.RawMessage
+	err := c.CallContext(ctx, &raw, "debug_traceTransaction", txHash, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCallFrame(raw)
+}
+
+func decodeCallFrame(raw json.RawMessage) ([]*Tx, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, ethereum.NotFound
+	}
+	var frame callFrame
+	if err := json.Unmarshal(raw, &frame); err != nil {
+		return nil, err
+	}
+	return frame.flatten(nil, []int{}), nil
+}
+
+// parityTrace is the flat trace returned by trace_transaction
+type parityTrace struct {
+	Type   string `json:"type"`
+	Action struct {
+		CallType       string          `json:"callType"`
+		CreationMethod string          `json:"creationMethod"`
+		From           common.Address  `json:"from"`
+		To             *common.Address `json:"to"`
+		Gas            hexutil.Uint64  `json:"gas"`
+		Input          hexutil.Bytes   `json:"input"`
+		Init           hexutil.Bytes   `json:"init"`
+		Value          *hexutil.Big    `json:"value"`
+		Address        common.Address  `json:"address"`       // selfdestruct
+		RefundAddress  *common.Address `json:"refundAddress"` // selfdestruct
+		Balance        *hexutil.Big    `json:"balance"`       // selfdestruct
+	} `json:"action"`
+	Result *struct {
+		GasUsed hexutil.Uint64  `json:"gasUsed"`
+		Output  hexutil.Bytes   `json:"output"`
+		Address *common.Address `json:"address"` // create
+		Code    hexutil.Bytes   `json:"code"`    // create
+	} `json:"result"`
+	Error           string       `json:"error"`
+	TraceAddress    []int        `json:"traceAddress"`
+	TransactionHash *common.Hash `json:"transactionHash"`
+}
+
+func (p *parityTrace) toTx() *Tx {
+	action := p.Action
+	tx := &Tx{
+		From:         action.From,
+		To:           action.To,
+		Input:        action.Input,
+		Value:        (*big.Int)(action.Value),
+		Gas:          uint64(action.Gas),
+		Error:        p.Error,
+		Depth:        len(p.TraceAddress),
+		TraceAddress: p.TraceAddress,
+	}
+	if tx.TraceAddress == nil {
+		tx.TraceAddress = []int{}
+	}
+
+	switch p.Type {
+	case "call":
+		tx.Type = action.CallType
+	case "create":
+		tx.Type = CallTypeCreate
+		if action.CreationMethod == CallTypeCreate2 {
+			tx.Type = CallTypeCreate2
+		}
+		tx.Input = action.Init
+	case "suicide":
+		tx.Type = CallTypeSelfDestruct
+		tx.From = action.Address
+		tx.To = action.RefundAddress
+		tx.Value = (*big.Int)(action.Balance)
+	default:
+		tx.Type = p.Type
+	}
+	if p.Result != nil {
+		tx.GasUsed = uint64(p.Result.GasUsed)
+		tx.Output = p.Result.Output
+		if p.Type == "create" {
+			tx.To = p.Result.Address
+			tx.Output = p.Result.Code
+		}
+	}
+	if tx.Value == nil {
+		tx.Value = new(big.Int)
+	}
+	tx.RevertReason = revertReason(tx.Error, tx.Output)
+	return tx
+}
+
+// TraceParity traces the transaction with trace_transaction.
+func TraceParity(c *rpc.Client, ctx context.Context, txHash common.Hash) ([]*Tx, error) {
+	var traces []*parityTrace
+	if err := c.CallContext(ctx, &traces, "trace_transaction", txHash); err != nil {
+		return nil, err
+	}
+	if len(traces) == 0 {
+		return nil, ethereum.NotFound
+	}
+
+	txs := make([]*Tx, 0, len(traces))
+	for _, trace := range traces {
+		txs = append(txs, trace.toTx())
+	}
+	return txs, nil
+}
@@ -0,0 +1,251 @@
+package tracer
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	testCallFrame = `{"type":"CALL","from":"0x97549e368acafdcae786bb93d98379f1d1561a29","to":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54",
+		"value":"0x400","gas":"0x3d59","gasUsed":"0x3a69","input":"0x2e1a7d4d","output":"0x",
+		"calls":[
+			{"type":"DELEGATECALL","from":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54","to":"0x82a3a88bc9d6a70c4f3c66534566892eae0cad81",
+			 "gas":"0x2000","gasUsed":"0x1000","input":"0x2e1a7d4d",
+			 "calls":[{"type":"CALL","from":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54","to":"0x97549e368acafdcae786bb93d98379f1d1561a29",
+			           "value":"0x400","gas":"0x900","gasUsed":"0x0","input":"0x"}]},
+			{"type":"CREATE2","from":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54","to":"0x415ba9d241de28eabeac9fa33379d5f923d76956",
+			 "value":"0x0","gas":"0x5000","gasUsed":"0x4000","input":"0x6080","output":"0x6001"}
+		]}`
+
+	testParityTraces = `[
+		{"type":"call","action":{"callType":"call","from":"0x97549e368acafdcae786bb93d98379f1d1561a29","to":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54",
+		 "gas":"0x3d59","input":"0x2e1a7d4d","value":"0x400"},"result":{"gasUsed":"0x3a69","output":"0x"},"subtraces":2,"traceAddress":[]},
+		{"type":"call","action":{"callType":"delegatecall","from":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54","to":"0x82a3a88bc9d6a70c4f3c66534566892eae0cad81",
+		 "gas":"0x2000","input":"0x2e1a7d4d","value":"0x0"},"result":{"gasUsed":"0x1000","output":"0x"},"subtraces":1,"traceAddress":[0]},
+		{"type":"call","action":{"callType":"call","from":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54","to":"0x97549e368acafdcae786bb93d98379f1d1561a29",
+		 "gas":"0x900","input":"0x","value":"0x400"},"result":{"gasUsed":"0x0","output":"0x"},"subtraces":0,"traceAddress":[0,0]},
+		{"type":"create","action":{"creationMethod":"create2","from":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54","gas":"0x5000","init":"0x6080","value":"0x0"},
+		 "result":{"address":"0x415ba9d241de28eabeac9fa33379d5f923d76956","code":"0x6001","gasUsed":"0x4000"},"subtraces":0,"traceAddress":[1]},
+		{"type":"suicide","action":{"address":"0x415ba9d241de28eabeac9fa33379d5f923d76956","refundAddress":"0x97549e368acafdcae786bb93d98379f1d1561a29","balance":"0x1"},
+		 "result":null,"subtraces":0,"traceAddress":[2]}
+	]`
+)
+
+func TestFlattenCallFrame(t *testing.T) {
+	txs, err := decodeCallFrame(json.RawMessage(testCallFrame))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkTestTxs(t, txs, []string{CallTypeCall, CallTypeDelegateCall, CallTypeCall, CallTypeCreate2},
+		[][]int{{}, {0}, {0, 0}, {1}})
+	if txs[1].Value == nil || txs[1].Value.Sign() != 0 {
+		t.Errorf("delegatecall value %v, want 0", txs[1].Value)
+	}
+}
+
+func TestParityTraces(t *testing.T) {
+	var traces []*parityTrace
+	if err := json.Unmarshal([]byte(testParityTraces), &traces); err != nil {
+		t.Fatal(err)
+	}
+	txs := make([]*Tx, 0, len(traces))
+	for _, trace := range traces {
+		txs = append(txs, trace.toTx())
+	}
+	checkTestTxs(t, txs, []string{CallTypeCall, CallTypeDelegateCall, CallTypeCall, CallTypeCreate2, CallTypeSelfDestruct},
+		[][]int{{}, {0}, {0, 0}, {1}, {2}})
+
+	selfdestruct := txs[4]
+	if selfdestruct.From != common.HexToAddress("0x415ba9d241de28eabeac9fa33379d5f923d76956") ||
+		selfdestruct.To == nil || *selfdestruct.To != common.HexToAddress("0x97549e368acafdcae786bb93d98379f1d1561a29") ||
+		selfdestruct.Value.Uint64() != 1 {
+		t.Errorf("selfdestruct %+v", selfdestruct)
+	}
+}
+
+// checkTestTxs checks the call frames normalized from the test traces.
+func checkTestTxs(t *testing.T, txs []*Tx, types []string, traceAddresses [][]int) {
+	t.Helper()
+	if len(txs) != len(types) {
+		t.Fatalf("%d calls, want %d", len(txs), len(types))
+	}
+	for i, tx := range txs {
+		if tx.Type != types[i] {
+			t.Errorf("call %d type %s, want %s", i, tx.Type, types[i])
+		}
+		if len(tx.TraceAddress) != len(traceAddresses[i]) || tx.Depth != len(traceAddresses[i]) {
+			t.Errorf("call %d trace address %v depth %d, want %v", i, tx.TraceAddress, tx.Depth, traceAddresses[i])
+			continue
+		}
+		for j := range tx.TraceAddress {
+			if tx.TraceAddress[j] != traceAddresses[i][j] {
+				t.Errorf("call %d trace address %v, want %v", i, tx.TraceAddress, traceAddresses[i])
+			}
+		}
+	}
+	if txs[0].Gas != 0x3d59 || txs[0].GasUsed != 0x3a69 || txs[0].Value.Uint64() != 0x400 {
+		t.Errorf("call 0 %+v", txs[0])
+	}
+	create := txs[3]
+	if create.To == nil || *create.To != common.HexToAddress("0x415ba9d241de28eabeac9fa33379d5f923d76956") ||
+		common.Bytes2Hex(create.Input) != "6080" || common.Bytes2Hex(create.Output) != "6001" {
+		t.Errorf("create %+v", create)
+	}
+	if !txs[2].IsTransfer() || txs[1].IsTransfer() {
+		t.Error("want the call a transfer and the delegatecall not")
+	}
+}
+
+// newTestRPCServer serves the methods with the results, the others fail.
+func newTestRPCServer(t *testing.T, results map[string]string) *rpc.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		json.Unmarshal(body, &req)
+		key := req.Method
+		if len(req.Params) > 1 {
+			var config TraceConfig
+			json.Unmarshal(req.Params[1], &config)
+			// the built-in tracers by name, the js tracers are code
+			if config.Tracer != "" && !strings.Contains(config.Tracer, "{") {
+				key += "/" + config.Tracer
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if result, ok := results[key]; ok {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":` + result + `}`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"error":{"code":-32601,"message":"the method ` + key + ` is not available"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := rpc.Dial(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestTracerDetect(t *testing.T) {
+	hash := common.HexToHash("0x89132e841b36fbe8f2ee3a1ba9bda4a3db5d59bb06458955802c17ba5c1fbd84")
+	ctx := context.Background()
+
+	tests := []struct {
+		results map[string]string
+		want    Backend
+	}{
+		{map[string]string{"debug_traceTransaction/callTracer": testCallFrame}, BackendCallTracer},
+		{map[string]string{"trace_transaction": testParityTraces}, BackendParity},
+	}
+	for _, tt := range tests {
+		tracer := New(newTestRPCServer(t, tt.results), BackendAuto)
+		txs, err := tracer.TraceTransaction(ctx, hash, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tracer.Backend() != tt.want {
+			t.Errorf("backend %s, want %s", tracer.Backend(), tt.want)
+		}
+		if len(txs) < 4 || txs[1].Type != CallTypeDelegateCall {
+			t.Errorf("%s traced %d calls", tt.want, len(txs))
+		}
+	}
+
+	tracer := New(newTestRPCServer(t, nil), BackendAuto)
+	if _, err := tracer.TraceTransaction(ctx, hash, nil); err == nil {
+		t.Error("want error without any tracing API")
+	}
+	if tracer.Backend() != BackendAuto {
+		t.Errorf("backend %s, want auto after the failed detection", tracer.Backend())
+	}
+}
+
+func TestTraceBlock(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		hash1  = common.HexToHash("0x01")
+		hash2  = common.HexToHash("0x02")
+		block  = `{"transactions":["` + hash1.String() + `","` + hash2.String() + `"]}`
+		frames = `[{"result":` + testCallFrame + `},{"error":"execution timeout"}]`
+	)
+	parity := `[` + testParityTraces[1:len(testParityTraces)-1] + `]`
+	var traces []map[string]interface{}
+	if err := json.Unmarshal([]byte(parity), &traces); err != nil {
+		t.Fatal(err)
+	}
+	for i, trace := range traces {
+		trace["transactionHash"] = hash1.String()
+		if i >= len(traces)-2 {
+			trace["transactionHash"] = hash2.String()
+		}
+	}
+	traces = append(traces, map[string]interface{}{"type": "reward", "action": map[string]interface{}{}, "traceAddress": []int{}})
+	b, _ := json.Marshal(traces)
+	parity = string(b)
+
+	tests := []struct {
+		results map[string]string
+		backend Backend
+		want    []int // the transfers of the txs, -1 for the trace error
+	}{
+		{map[string]string{"eth_getBlockByNumber": block, "debug_traceBlockByNumber/callTracer": frames}, BackendCallTracer, []int{1, -1}},
+		{map[string]string{"eth_getBlockByNumber": block, "trace_block": parity}, BackendParity, []int{1, 1}},
+		// fall back to trace the txs one by one
+		{map[string]string{"eth_getBlockByNumber": block, "trace_transaction": testParityTraces}, BackendParity, []int{2, 2}},
+	}
+	for i, tt := range tests {
+		tracer := New(newTestRPCServer(t, tt.results), BackendAuto)
+		txTraces, err := tracer.TraceBlock(ctx, 100, nil, 2)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if tracer.Backend() != tt.backend {
+			t.Errorf("test %d: backend %s, want %s", i, tracer.Backend(), tt.backend)
+		}
+		if len(txTraces) != len(tt.want) {
+			t.Fatalf("test %d: %d traces, want %d", i, len(txTraces), len(tt.want))
+		}
+		for j, trace := range txTraces {
+			got := len(trace.Transfers())
+			if trace.Error != "" {
+				got = -1
+			}
+			if got != tt.want[j] {
+				t.Errorf("test %d tx %d: %d transfers, want %d (%s)", i, j, got, tt.want[j], trace.Error)
+			}
+		}
+		if txTraces[0].Hash != hash1 || txTraces[1].Hash != hash2 {
+			t.Errorf("test %d: traces of %x %x", i, txTraces[0].Hash, txTraces[1].Hash)
+		}
+	}
+}
+
+func TestCallContext(t *testing.T) {
+	timeout := "2s"
+	ctx, cancel := callContext(context.Background(), &TraceConfig{Timeout: &timeout})
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > 2*time.Second+timeoutMargin {
+		t.Errorf("deadline %v, want in %v", deadline, 2*time.Second+timeoutMargin)
+	}
+	ctx, cancel = callContext(context.Background(), nil)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("want no deadline without the timeout")
+	}
+}
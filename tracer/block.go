@@ -0,0 +1,212 @@
+package tracer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// timeoutMargin is waited after the Timeout of the config for the node to
+// return the timeout error of the trace.
+const timeoutMargin = 5 * time.Second
+
+// TxTrace is the call frames of a transaction in the traced block
+type TxTrace struct {
+	Hash  common.Hash `json:"hash"`
+	Calls []*Tx       `json:"calls"`
+	Error string      `json:"error,omitempty"`
+}
+
+// Transfers returns the calls moving value inside the transaction, the
+// transaction itself and the reverted calls are skipped.
+func (t *TxTrace) Transfers() []*Tx {
+	var transfers []*Tx
+	for i := 1; i < len(t.Calls); i++ {
+		if t.Calls[i].IsTransfer() && !IsReverted(t.Calls, i) {
+			transfers = append(transfers, t.Calls[i])
+		}
+	}
+	return transfers
+}
+
+// callContext bounds the trace call by the Timeout of the config.
+func callContext(ctx context.Context, config *TraceConfig) (context.Context, context.CancelFunc) {
+	if config == nil || config.Timeout == nil {
+		return context.WithCancel(ctx)
+	}
+	timeout, err := time.ParseDuration(*config.Timeout)
+	if err != nil {
+		// the node rejects the invalid timeout
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout+timeoutMargin)
+}
+
+// blockTxHashes returns the transaction hashes of the block in order.
+func blockTxHashes(c *rpc.Client, ctx context.Context, number uint64) ([]common.Hash, error) {
+	var block *struct {
+		Transactions []common.Hash `json:"transactions"`
+	}
+	if err := c.CallContext(ctx, &block, "eth_getBlockByNumber", hexutil.EncodeUint64(number), false); err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, ethereum.NotFound
+	}
+	return block.Transactions, nil
+}
+
+// blockTraceResult is the trace of a transaction by debug_traceBlockByNumber
+type blockTraceResult struct {
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+// traceBlockDebug traces the block with debug_traceBlockByNumber, the
+// results are in the order of the transactions.
+func traceBlockDebug(c *rpc.Client, ctx context.Context, number uint64, hashes []common.Hash, config *TraceConfig, backend Backend) ([]*TxTrace, error) {
+	cfg := TraceConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if backend == BackendCallTracer {
+		cfg.Tracer = "callTracer"
+	} else if cfg.Tracer == "" {
+		tjs, err := TracerJS()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Tracer = string(tjs)
+	}
+
+	var results []*blockTraceResult
+	if err := c.CallContext(ctx, &results, "debug_traceBlockByNumber", hexutil.EncodeUint64(number), &cfg); err != nil {
+		return nil, err
+	}
+	if len(results) != len(hashes) {
+		return nil, fmt.Errorf("block %d: %d traces of %d transactions", number, len(results), len(hashes))
+	}
+
+	traces := make([]*TxTrace, len(hashes))
+	for i, result := range results {
+		trace := &TxTrace{Hash: hashes[i], Error: result.Error}
+		if result.Error == "" {
+			var err error
+			if backend == BackendCallTracer {
+				trace.Calls, err = decodeCallFrame(result.Result)
+			} else {
+				trace.Calls, err = decodeJSCalls(result.Result)
+			}
+			if err != nil {
+				trace.Error = err.Error()
+			}
+		}
+		traces[i] = trace
+	}
+	return traces, nil
+}
+
+// traceBlockParity traces the block with trace_block and groups the traces by
+// the transaction.
+func traceBlockParity(c *rpc.Client, ctx context.Context, number uint64, hashes []common.Hash) ([]*TxTrace, error) {
+	var results []*parityTrace
+	if err := c.CallContext(ctx, &results, "trace_block", hexutil.EncodeUint64(number)); err != nil {
+		return nil, err
+	}
+
+	traces := make([]*TxTrace, len(hashes))
+	byHash := make(map[common.Hash]*TxTrace, len(hashes))
+	for i, hash := range hashes {
+		traces[i] = &TxTrace{Hash: hash}
+		byHash[hash] = traces[i]
+	}
+	for _, result := range results {
+		// the block rewards have no transaction
+		if result.TransactionHash == nil {
+			continue
+		}
+		trace, ok := byHash[*result.TransactionHash]
+		if !ok {
+			return nil, fmt.Errorf("block %d: trace of unknown transaction %s", number, result.TransactionHash.String())
+		}
+		trace.Calls = append(trace.Calls, result.toTx())
+	}
+	return traces, nil
+}
+
+// traceBlockTxs traces the transactions one by one concurrently.
+func (t *Tracer) traceBlockTxs(ctx context.Context, hashes []common.Hash, config *TraceConfig, concurrency int) []*TxTrace {
+	traces := make([]*TxTrace, len(hashes))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				trace := &TxTrace{Hash: hashes[i]}
+				calls, err := t.TraceTransaction(ctx, hashes[i], config)
+				if err != nil {
+					trace.Error = err.Error()
+				}
+				trace.Calls = calls
+				traces[i] = trace
+			}
+		}()
+	}
+	for i := range hashes {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return traces
+}
+
+// TraceBlock traces all the transactions of the block, it falls back to
+// trace the transactions one by one if the node can not trace the block.
+// The failed transaction traces have the Error set.
+func (t *Tracer) TraceBlock(ctx context.Context, number uint64, config *TraceConfig, concurrency int) ([]*TxTrace, error) {
+	hashes, err := blockTxHashes(t.client, ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("get block %d error: %v", number, err)
+	}
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	backends := []Backend{t.Backend()}
+	if backends[0] == BackendAuto {
+		backends = detectOrder
+	}
+	for _, backend := range backends {
+		callCtx, cancel := callContext(ctx, config)
+		var traces []*TxTrace
+		if backend == BackendParity {
+			traces, err = traceBlockParity(t.client, callCtx, number, hashes)
+		} else {
+			traces, err = traceBlockDebug(t.client, callCtx, number, hashes, config, backend)
+		}
+		cancel()
+		if err == nil {
+			t.mu.Lock()
+			t.backend = backend
+			t.mu.Unlock()
+			return traces, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	return t.traceBlockTxs(ctx, hashes, config, concurrency), nil
+}
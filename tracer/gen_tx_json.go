@@ -15,34 +15,58 @@ var _ = (*txMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (t Tx) MarshalJSON() ([]byte, error) {
 	type Tx struct {
-		From   common.Address  `db:"from"`
-		To     *common.Address `db:"to"`
-		Input  hexutil.Bytes   `db:"input"`
-		Output hexutil.Bytes   `db:"output"`
-		Value  *hexutil.Big    `db:"value"`
+		Type         string          `db:"type"`
+		From         common.Address  `db:"from"`
+		To           *common.Address `db:"to"`
+		Input        hexutil.Bytes   `db:"input"`
+		Output       hexutil.Bytes   `db:"output"`
+		Value        *hexutil.Big    `db:"value"`
+		Gas          hexutil.Uint64  `db:"gas"`
+		GasUsed      hexutil.Uint64  `db:"gas_used"`
+		Error        string          `db:"error"`
+		RevertReason string          `db:"revert_reason"`
+		Depth        int             `db:"depth"`
+		TraceAddress []int           `db:"trace_address"`
 	}
 	var enc Tx
+	enc.Type = t.Type
 	enc.From = t.From
 	enc.To = t.To
 	enc.Input = t.Input
 	enc.Output = t.Output
 	enc.Value = (*hexutil.Big)(t.Value)
+	enc.Gas = hexutil.Uint64(t.Gas)
+	enc.GasUsed = hexutil.Uint64(t.GasUsed)
+	enc.Error = t.Error
+	enc.RevertReason = t.RevertReason
+	enc.Depth = t.Depth
+	enc.TraceAddress = t.TraceAddress
 	return json.Marshal(&enc)
 }
 
 // UnmarshalJSON unmarshals from JSON.
 func (t *Tx) UnmarshalJSON(input []byte) error {
 	type Tx struct {
-		From   *common.Address `db:"from"`
-		To     *common.Address `db:"to"`
-		Input  *hexutil.Bytes  `db:"input"`
-		Output *hexutil.Bytes  `db:"output"`
-		Value  *hexutil.Big    `db:"value"`
+		Type         *string         `db:"type"`
+		From         *common.Address `db:"from"`
+		To           *common.Address `db:"to"`
+		Input        *hexutil.Bytes  `db:"input"`
+		Output       *hexutil.Bytes  `db:"output"`
+		Value        *hexutil.Big    `db:"value"`
+		Gas          *hexutil.Uint64 `db:"gas"`
+		GasUsed      *hexutil.Uint64 `db:"gas_used"`
+		Error        *string         `db:"error"`
+		RevertReason *string         `db:"revert_reason"`
+		Depth        *int            `db:"depth"`
+		TraceAddress []int           `db:"trace_address"`
 	}
 	var dec Tx
 	if err := json.Unmarshal(input, &dec); err != nil {
 		return err
 	}
+	if dec.Type != nil {
+		t.Type = *dec.Type
+	}
 	if dec.From != nil {
 		t.From = *dec.From
 	}
@@ -58,5 +82,23 @@ func (t *Tx) UnmarshalJSON(input []byte) error {
 	if dec.Value != nil {
 		t.Value = (*big.Int)(dec.Value)
 	}
+	if dec.Gas != nil {
+		t.Gas = uint64(*dec.Gas)
+	}
+	if dec.GasUsed != nil {
+		t.GasUsed = uint64(*dec.GasUsed)
+	}
+	if dec.Error != nil {
+		t.Error = *dec.Error
+	}
+	if dec.RevertReason != nil {
+		t.RevertReason = *dec.RevertReason
+	}
+	if dec.Depth != nil {
+		t.Depth = *dec.Depth
+	}
+	if dec.TraceAddress != nil {
+		t.TraceAddress = dec.TraceAddress
+	}
 	return nil
 }
@@ -0,0 +1,50 @@
+package tracer
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The built-in tracers embedded in the binary
+const (
+	TracerInternalTx = "internal-tx" // the call frames like Parity's trace_replayTransaction
+	Tracer4Byte      = "4byte"       // the counts of the 4-byte method ids with the call data sizes
+	TracerPrestate   = "prestate"    // the accounts and storage touched before the transaction
+)
+
+//go:embed tracers/*.js
+var tracers embed.FS
+
+// Tracers returns the names of the built-in tracers.
+func Tracers() []string {
+	entries, err := tracers.ReadDir("tracers")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".js"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupTracer returns the javascript code of the built-in tracer.
+func LookupTracer(name string) (string, error) {
+	code, err := tracers.ReadFile("tracers/" + name + ".js")
+	if err != nil {
+		return "", fmt.Errorf("unknown built-in tracer %s, use one of %s", name, strings.Join(Tracers(), ", "))
+	}
+	return string(code), nil
+}
+
+// TracerJS returns the code of the internal-tx tracer.
+func TracerJS() ([]byte, error) {
+	code, err := LookupTracer(TracerInternalTx)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(code), nil
+}
@@ -0,0 +1,34 @@
+package tracer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLookupTracer(t *testing.T) {
+	want := []string{Tracer4Byte, TracerInternalTx, TracerPrestate}
+	if names := Tracers(); !reflect.DeepEqual(names, want) {
+		t.Fatalf("tracers %v, want %v", names, want)
+	}
+	for _, name := range want {
+		code, err := LookupTracer(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(code, "result") {
+			t.Errorf("tracer %s has no result function", name)
+		}
+	}
+	if _, err := LookupTracer("callTracer"); err == nil {
+		t.Error("want error of the unknown tracer")
+	}
+
+	tjs, err := TracerJS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := LookupTracer(TracerInternalTx); string(tjs) != code {
+		t.Error("TracerJS is not the internal-tx tracer")
+	}
+}
@@ -0,0 +1,188 @@
+package tracer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// errNoDiffMode is returned by the nodes whose prestateTracer only returns
+// the prestate.
+var errNoDiffMode = errors.New("the prestateTracer of the node does not support the diff mode")
+
+// prestateAccount is an account returned by prestateTracer, only the changed
+// fields are set in the diff mode.
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   *uint64                     `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// prestateDiff is the result of prestateTracer in the diff mode
+type prestateDiff struct {
+	Pre  map[common.Address]*prestateAccount `json:"pre"`
+	Post map[common.Address]*prestateAccount `json:"post"`
+}
+
+// BalanceChange is the balance of the account before and after the transaction
+type BalanceChange struct {
+	From *hexutil.Big `json:"from"`
+	To   *hexutil.Big `json:"to"`
+}
+
+// Delta returns the balance difference made by the transaction.
+func (c *BalanceChange) Delta() *big.Int {
+	return new(big.Int).Sub(c.To.ToInt(), c.From.ToInt())
+}
+
+// NonceChange is the nonce of the account before and after the transaction
+type NonceChange struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+// CodeChange is the code of the account before and after the transaction
+type CodeChange struct {
+	From hexutil.Bytes `json:"from"`
+	To   hexutil.Bytes `json:"to"`
+}
+
+// StorageChange is a storage slot before and after the transaction
+type StorageChange struct {
+	Slot common.Hash `json:"slot"`
+	From common.Hash `json:"from"`
+	To   common.Hash `json:"to"`
+}
+
+// AccountDiff is the changes of an account touched by the transaction
+type AccountDiff struct {
+	Address common.Address   `json:"address"`
+	Created bool             `json:"created,omitempty"`
+	Deleted bool             `json:"deleted,omitempty"`
+	Balance *BalanceChange   `json:"balance,omitempty"`
+	Nonce   *NonceChange     `json:"nonce,omitempty"`
+	Code    *CodeChange      `json:"code,omitempty"`
+	Storage []*StorageChange `json:"storage,omitempty"`
+}
+
+// TraceStateDiff traces the transaction with the built-in prestateTracer in
+// the diff mode and returns the changed accounts in the order of address.
+func TraceStateDiff(c *rpc.Client, ctx context.Context, txHash common.Hash, config *TraceConfig) ([]*AccountDiff, error) {
+	cfg := TraceConfig{Tracer: "prestateTracer", TracerConfig: json.RawMessage(`{"diffMode":true}`)}
+	if config != nil {
+		cfg.Timeout, cfg.Reexec = config.Timeout, config.Reexec
+	}
+	ctx, cancel := callContext(ctx, &cfg)
+	defer cancel()
+
+	var raw json.RawMessage
+	if err := c.CallContext(ctx, &raw, "debug_traceTransaction", txHash, &cfg); err != nil {
+		return nil, err
+	}
+	return decodeStateDiff(raw)
+}
+
+func decodeStateDiff(raw json.RawMessage) ([]*AccountDiff, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, ethereum.NotFound
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["pre"]; !ok || len(fields) > 2 {
+		return nil, errNoDiffMode
+	}
+	var result prestateDiff
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	var diffs []*AccountDiff
+	for address, pre := range result.Pre {
+		if diff := accountDiff(address, pre, result.Post[address]); diff != nil {
+			diffs = append(diffs, diff)
+		}
+	}
+	for address, post := range result.Post {
+		if _, ok := result.Pre[address]; ok {
+			continue
+		}
+		if diff := accountDiff(address, nil, post); diff != nil {
+			diffs = append(diffs, diff)
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return bytes.Compare(diffs[i].Address[:], diffs[j].Address[:]) < 0
+	})
+	return diffs, nil
+}
+
+// accountDiff compares the account before and after the transaction, the
+// account is created without the pre state and deleted without the post
+// state. It returns nil if nothing changed.
+func accountDiff(address common.Address, pre, post *prestateAccount) *AccountDiff {
+	diff := &AccountDiff{Address: address, Created: pre == nil, Deleted: post == nil}
+	if pre == nil {
+		pre = &prestateAccount{}
+	}
+	if post == nil {
+		// the balance and storage of the deleted account are cleared
+		post = &prestateAccount{Balance: new(hexutil.Big), Storage: map[common.Hash]common.Hash{}}
+		for slot := range pre.Storage {
+			post.Storage[slot] = common.Hash{}
+		}
+	}
+
+	preBalance := new(hexutil.Big)
+	if pre.Balance != nil {
+		preBalance = pre.Balance
+	}
+	if post.Balance != nil && post.Balance.ToInt().Cmp(preBalance.ToInt()) != 0 {
+		diff.Balance = &BalanceChange{From: preBalance, To: post.Balance}
+	}
+
+	var preNonce uint64
+	if pre.Nonce != nil {
+		preNonce = *pre.Nonce
+	}
+	if post.Nonce != nil && *post.Nonce != preNonce {
+		diff.Nonce = &NonceChange{From: preNonce, To: *post.Nonce}
+	}
+
+	if post.Code != nil && !bytes.Equal(pre.Code, post.Code) {
+		diff.Code = &CodeChange{From: pre.Code, To: post.Code}
+	}
+
+	// the slots cleared to zero are left out of the post state
+	slots := make(map[common.Hash]bool)
+	for slot := range pre.Storage {
+		slots[slot] = true
+	}
+	for slot := range post.Storage {
+		slots[slot] = true
+	}
+	for slot := range slots {
+		from, to := pre.Storage[slot], post.Storage[slot]
+		if from != to {
+			diff.Storage = append(diff.Storage, &StorageChange{Slot: slot, From: from, To: to})
+		}
+	}
+	sort.Slice(diff.Storage, func(i, j int) bool {
+		return bytes.Compare(diff.Storage[i].Slot[:], diff.Storage[j].Slot[:]) < 0
+	})
+
+	if !diff.Created && !diff.Deleted && diff.Balance == nil && diff.Nonce == nil && diff.Code == nil && len(diff.Storage) == 0 {
+		return nil
+	}
+	return diff
+}
@@ -0,0 +1,65 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testStateDiff = `{
+	"pre": {
+		"0x97549e368acafdcae786bb93d98379f1d1561a29": {"balance":"0xde0b6b3a7640000","nonce":1},
+		"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54": {"balance":"0x0","nonce":1,"code":"0x6080",
+			"storage":{"0x0000000000000000000000000000000000000000000000000000000000000001":"0x0000000000000000000000000000000000000000000000000000000000000005",
+			           "0x0000000000000000000000000000000000000000000000000000000000000002":"0x0000000000000000000000000000000000000000000000000000000000000007"}},
+		"0x82a3a88bc9d6a70c4f3c66534566892eae0cad81": {"balance":"0x10","nonce":1,"code":"0x6001"}
+	},
+	"post": {
+		"0x97549e368acafdcae786bb93d98379f1d1561a29": {"balance":"0x6f05b59d3b20000","nonce":2},
+		"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54": {"balance":"0x6f05b59d3b20000",
+			"storage":{"0x0000000000000000000000000000000000000000000000000000000000000001":"0x0000000000000000000000000000000000000000000000000000000000000006"}},
+		"0x415ba9d241de28eabeac9fa33379d5f923d76956": {"balance":"0x0","nonce":1,"code":"0x6001"}
+	}
+}`
+
+func TestStateDiff(t *testing.T) {
+	c := newTestRPCServer(t, map[string]string{"debug_traceTransaction/prestateTracer": testStateDiff})
+	diffs, err := TraceStateDiff(c, context.Background(), common.HexToHash("0x01"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 4 {
+		t.Fatalf("%d accounts, want 4", len(diffs))
+	}
+
+	created, wallet, deleted, sender := diffs[0], diffs[1], diffs[2], diffs[3]
+	if created.Address != common.HexToAddress("0x415ba9d241de28eabeac9fa33379d5f923d76956") || !created.Created ||
+		created.Code == nil || common.Bytes2Hex(created.Code.To) != "6001" || created.Nonce == nil || created.Nonce.To != 1 {
+		t.Errorf("created %+v", created)
+	}
+	if created.Balance != nil {
+		t.Errorf("created balance %+v, want unchanged", created.Balance)
+	}
+
+	if wallet.Nonce != nil || wallet.Code != nil || wallet.Balance == nil || wallet.Balance.Delta().String() != "500000000000000000" {
+		t.Errorf("wallet %+v", wallet)
+	}
+	if len(wallet.Storage) != 2 || wallet.Storage[0].To != common.HexToHash("0x06") ||
+		wallet.Storage[1].From != common.HexToHash("0x07") || wallet.Storage[1].To != (common.Hash{}) {
+		t.Errorf("wallet storage %+v %+v", wallet.Storage[0], wallet.Storage[1])
+	}
+
+	if !deleted.Deleted || deleted.Balance == nil || deleted.Balance.To.ToInt().Sign() != 0 {
+		t.Errorf("deleted %+v", deleted)
+	}
+
+	if sender.Nonce == nil || sender.Nonce.From != 1 || sender.Nonce.To != 2 || sender.Balance.Delta().String() != "-500000000000000000" {
+		t.Errorf("sender %+v", sender)
+	}
+
+	// the nodes without the diff mode return the prestate only
+	if _, err := decodeStateDiff([]byte(`{"0x97549e368acafdcae786bb93d98379f1d1561a29":{"balance":"0x0"}}`)); err != errNoDiffMode {
+		t.Errorf("error %v, want %v", err, errNoDiffMode)
+	}
+}
@@ -6,18 +6,12 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
-//go:generate go-bindata -pkg tracer -o gen_tracer.go tracer.js
-//
-
-func TracerJS() ([]byte, error) {
-	return tracerJsBytes()
-}
-
 /*
 [
     {
@@ -37,26 +31,145 @@ func TracerJS() ([]byte, error) {
 
 //go:generate gencodec -type Tx -field-override txMarshaling -out gen_tx_json.go
 
+// The call types of Tx
+const (
+	CallTypeCall         = "call"
+	CallTypeCallCode     = "callcode"
+	CallTypeDelegateCall = "delegatecall"
+	CallTypeStaticCall   = "staticcall"
+	CallTypeCreate       = "create"
+	CallTypeCreate2      = "create2"
+	CallTypeSelfDestruct = "selfdestruct"
+)
+
+// Tx is a call frame of the traced transaction, the first one is the
+// transaction itself.
 type Tx struct {
-	From   common.Address  `db:"from"`
-	To     *common.Address `db:"to"`
-	Input  []byte          `db:"input"`
-	Output []byte          `db:"output"`
-	Value  *big.Int        `db:"value"`
+	Type         string          `db:"type"`
+	From         common.Address  `db:"from"`
+	To           *common.Address `db:"to"` // the created contract of create
+	Input        []byte          `db:"input"`
+	Output       []byte          `db:"output"` // the created code of create
+	Value        *big.Int        `db:"value"`
+	Gas          uint64          `db:"gas"`
+	GasUsed      uint64          `db:"gas_used"`
+	Error        string          `db:"error"`
+	RevertReason string          `db:"revert_reason"`
+	Depth        int             `db:"depth"`
+	TraceAddress []int           `db:"trace_address"`
 }
 
 type txMarshaling struct {
-	From   common.Address
-	To     *common.Address
-	Input  hexutil.Bytes
-	Output hexutil.Bytes
-	Value  *hexutil.Big
+	From    common.Address
+	To      *common.Address
+	Input   hexutil.Bytes
+	Output  hexutil.Bytes
+	Value   *hexutil.Big
+	Gas     hexutil.Uint64
+	GasUsed hexutil.Uint64
+}
+
+// IsTransfer reports if the call moves value, the delegatecall shares the
+// value of its caller and the staticcall has none.
+func (tx *Tx) IsTransfer() bool {
+	if tx.Value == nil || tx.Value.Sign() == 0 {
+		return false
+	}
+	return tx.Type != CallTypeDelegateCall && tx.Type != CallTypeStaticCall
+}
+
+// IsReverted reports if the call i or any of its callers failed, so its
+// effects are reverted.
+func IsReverted(txs []*Tx, i int) bool {
+	call := txs[i]
+	for _, tx := range txs {
+		if tx.Error == "" || len(tx.TraceAddress) > len(call.TraceAddress) {
+			continue
+		}
+		caller := true
+		for j, n := range tx.TraceAddress {
+			if call.TraceAddress[j] != n {
+				caller = false
+				break
+			}
+		}
+		if caller {
+			return true
+		}
+	}
+	return false
+}
+
+// jsCall is the call frame returned by tracer.js
+type jsCall struct {
+	Type                       string          `json:"type"`
+	CallType                   string          `json:"callType"`
+	From                       common.Address  `json:"from"`
+	To                         *common.Address `json:"to"`
+	Input                      hexutil.Bytes   `json:"input"`
+	Init                       hexutil.Bytes   `json:"init"`
+	Output                     hexutil.Bytes   `json:"output"`
+	CreatedContractAddressHash *common.Address `json:"createdContractAddressHash"`
+	CreatedContractCode        hexutil.Bytes   `json:"createdContractCode"`
+	Value                      *hexutil.Big    `json:"value"`
+	Gas                        hexutil.Uint64  `json:"gas"`
+	GasUsed                    hexutil.Uint64  `json:"gasUsed"`
+	Error                      string          `json:"error"`
+	TraceAddress               []int           `json:"traceAddress"`
+}
+
+func (c *jsCall) toTx() *Tx {
+	tx := &Tx{
+		Type:         c.Type,
+		From:         c.From,
+		To:           c.To,
+		Input:        c.Input,
+		Output:       c.Output,
+		Value:        (*big.Int)(c.Value),
+		Gas:          uint64(c.Gas),
+		GasUsed:      uint64(c.GasUsed),
+		Error:        c.Error,
+		Depth:        len(c.TraceAddress),
+		TraceAddress: c.TraceAddress,
+	}
+	if tx.TraceAddress == nil {
+		tx.TraceAddress = []int{}
+	}
+	switch c.Type {
+	case CallTypeCall:
+		if c.CallType != "" {
+			tx.Type = c.CallType
+		}
+	case CallTypeCreate, CallTypeCreate2:
+		tx.To = c.CreatedContractAddressHash
+		tx.Input = c.Init
+		if c.Error == "" {
+			// the output of the failed creation is the revert data
+			tx.Output = c.CreatedContractCode
+		}
+	}
+	tx.RevertReason = revertReason(tx.Error, tx.Output)
+	return tx
+}
+
+// revertReason returns the reason of the reverted call with the output of
+// Error(string).
+func revertReason(err string, output []byte) string {
+	if err == "" || len(output) == 0 {
+		return ""
+	}
+	reason, unpackErr := abi.UnpackRevert(output)
+	if unpackErr != nil {
+		return ""
+	}
+	return reason
 }
 
 type TraceConfig struct {
-	Tracer  string  `json:"tracer,omitempty"`
-	Timeout *string `json:"timeout,omitempty"`
-	Reexec  *uint64 `json:"reexec,omitempty"`
+	Tracer       string          `json:"tracer,omitempty"`
+	TracerConfig json.RawMessage `json:"tracerConfig,omitempty"` // the config of the built-in tracer like {"diffMode":true}
+	Timeout      *string         `json:"timeout,omitempty"`
+	Reexec       *uint64         `json:"reexec,omitempty"`
 }
 
 func TraceTransaction(c *rpc.Client, ctx context.Context, txHash common.Hash, config *TraceConfig) ([]*Tx, error) {
@@ -65,29 +178,54 @@ func TraceTransaction(c *rpc.Client, ctx context.Context, txHash common.Hash, co
 		return nil, err
 	}
 
-	if config == nil {
-		config = &TraceConfig{
-			Tracer: string(tjs),
-		}
+	cfg := TraceConfig{}
+	if config != nil {
+		cfg = *config
 	}
-	if config.Tracer == "" {
-		config.Tracer = string(tjs)
+	if cfg.Tracer == "" {
+		cfg.Tracer = string(tjs)
 	}
 
 	var raw json.RawMessage
-	err = c.CallContext(ctx, &raw, "debug_traceTransaction", txHash, config)
+	err = c.CallContext(ctx, &raw, "debug_traceTransaction", txHash, &cfg)
 	if err != nil {
 		return nil, err
-	} else if len(raw) == 0 {
+	}
+
+	return decodeJSCalls(raw)
+}
+
+// TraceRaw traces the transaction with the tracer of the config, the
+// built-in struct logger if not set, and returns the result as it is.
+func TraceRaw(c *rpc.Client, ctx context.Context, txHash common.Hash, config *TraceConfig) (json.RawMessage, error) {
+	ctx, cancel := callContext(ctx, config)
+	defer cancel()
+
+	var raw json.RawMessage
+	if err := c.CallContext(ctx, &raw, "debug_traceTransaction", txHash, config); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
 		return nil, ethereum.NotFound
 	}
+	return raw, nil
+}
 
-	var txs []*Tx
+// decodeJSCalls decodes the result of tracer.js.
+func decodeJSCalls(raw json.RawMessage) ([]*Tx, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, ethereum.NotFound
+	}
 
-	err = json.Unmarshal(raw, &txs)
-	if err != nil {
+	var calls []*jsCall
+	if err := json.Unmarshal(raw, &calls); err != nil {
 		return nil, err
 	}
 
+	txs := make([]*Tx, 0, len(calls))
+	for _, call := range calls {
+		txs = append(txs, call.toTx())
+	}
+
 	return txs, nil
 }
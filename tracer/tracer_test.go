@@ -3,52 +3,105 @@ package tracer
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"testing"
 
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/newtonproject/newcommander/internal/rpctest"
 )
 
 func TestTracer(t *testing.T) {
-	rpcurl := "https://devnet.newchain.cloud.diynova.com/debug"
-
-	ctx := context.Background()
-	c, err := rpc.DialContext(ctx, rpcurl)
+	s := rpctest.NewServerFile(t, "testdata/trace_internal_tx.json")
+	c, err := rpc.Dial(s.URL())
 	if err != nil {
-		fmt.Println(err)
-		return
+		t.Fatal(err)
 	}
+	defer c.Close()
 
 	// hash := common.HexToHash("0x33fcd1b345b466dddec615edb041efc614d35cdbdc3434584db4fcb083f5fa81") // tx
 	hash := common.HexToHash("0x89132e841b36fbe8f2ee3a1ba9bda4a3db5d59bb06458955802c17ba5c1fbd84") // internal tx
 
-	tjs, err := TracerJS()
+	timeout, reexec := "5m", uint64(1024)
+	config := &TraceConfig{Timeout: &timeout, Reexec: &reexec}
+	txs, err := New(c, BackendJS).TraceTransaction(context.Background(), hash, config)
 	if err != nil {
-		fmt.Println(err)
-		return
+		t.Fatal(err)
 	}
 
-	config := struct {
-		Tracer  string `json:"tracer"`
-		Timeout string `json:"timeout"`
-		Reexec  uint64 `json:"reexec"`
-	}{
-		Tracer:  string(tjs),
-		Timeout: "5m",
-		Reexec:  1024, // blocks
+	out, err := json.MarshalIndent(txs, "", "  ")
+	if err != nil {
+		t.Fatal(err)
 	}
+	rpctest.Golden(t, "testdata/trace_internal_tx.golden", append(out, '\n'))
+}
 
-	var raw json.RawMessage
-	err = c.CallContext(ctx, &raw, "debug_traceTransaction", hash, config)
-	if err != nil {
-		fmt.Println(err)
-		return
-	} else if len(raw) == 0 {
-		fmt.Println(ethereum.NotFound)
-		return
+func TestDecodeCalls(t *testing.T) {
+	raw := `[
+		{"type":"call","callType":"call","from":"0x97549e368acafdcae786bb93d98379f1d1561a29","to":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54",
+		 "input":"0x2e1a7d4d","output":"0x","traceAddress":[],"value":"0x400","gas":"0x3d59","gasUsed":"0x3a69"},
+		{"type":"call","callType":"delegatecall","from":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54","to":"0x82a3a88bc9d6a70c4f3c66534566892eae0cad81",
+		 "input":"0x2e1a7d4d","output":"0x","traceAddress":[0],"value":"0x400","gas":"0x2000","gasUsed":"0x1000"},
+		{"type":"call","callType":"call","from":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54","to":"0x1f9840a85d5af5bf1d1762f925bdaddc4201f984",
+		 "input":"0xd0e30db0","error":"execution reverted","output":"0x08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000d696e73756666696369656e742100000000000000000000000000000000000000",
+		 "traceAddress":[0,0],"value":"0x400","gas":"0x900","gasUsed":"0x2f1"},
+		{"type":"call","callType":"call","from":"0x1f9840a85d5af5bf1d1762f925bdaddc4201f984","to":"0x97549e368acafdcae786bb93d98379f1d1561a29",
+		 "input":"0x","output":"0x","traceAddress":[0,0,0],"value":"0x1","gas":"0x0","gasUsed":"0x0"},
+		{"type":"create2","from":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54","init":"0x6080","createdContractAddressHash":"0x415ba9d241de28eabeac9fa33379d5f923d76956",
+		 "createdContractCode":"0x6001","traceAddress":[1],"value":"0x0","gas":"0x5000","gasUsed":"0x4000"},
+		{"type":"create","from":"0x570611ba2d46ff0aca9f96168c4acbdd27bb0c54","init":"0x6080","error":"execution reverted",
+		 "output":"0x08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000d696e73756666696369656e742100000000000000000000000000000000000000","traceAddress":[2],"value":"0x0","gas":"0x3000","gasUsed":"0x120"}
+	]`
+	var calls []*jsCall
+	if err := json.Unmarshal([]byte(raw), &calls); err != nil {
+		t.Fatal(err)
+	}
+	txs := make([]*Tx, 0, len(calls))
+	for _, call := range calls {
+		txs = append(txs, call.toTx())
 	}
 
-	fmt.Println(string(raw))
+	wantTypes := []string{CallTypeCall, CallTypeDelegateCall, CallTypeCall, CallTypeCall, CallTypeCreate2, CallTypeCreate}
+	wantTransfer := []bool{true, false, true, true, false, false}
+	wantReverted := []bool{false, false, true, true, false, true}
+	for i, tx := range txs {
+		if tx.Type != wantTypes[i] {
+			t.Errorf("call %d type %s, want %s", i, tx.Type, wantTypes[i])
+		}
+		if tx.Depth != len(tx.TraceAddress) {
+			t.Errorf("call %d depth %d, want %d", i, tx.Depth, len(tx.TraceAddress))
+		}
+		if tx.IsTransfer() != wantTransfer[i] {
+			t.Errorf("call %d transfer %v, want %v", i, tx.IsTransfer(), wantTransfer[i])
+		}
+		if IsReverted(txs, i) != wantReverted[i] {
+			t.Errorf("call %d reverted %v, want %v", i, IsReverted(txs, i), wantReverted[i])
+		}
+	}
+	if txs[0].Gas != 0x3d59 || txs[0].GasUsed != 0x3a69 {
+		t.Errorf("gas %d used %d", txs[0].Gas, txs[0].GasUsed)
+	}
+	if txs[2].RevertReason != "insufficient!" {
+		t.Errorf("revert reason %q, want %q", txs[2].RevertReason, "insufficient!")
+	}
+	if create := txs[4]; create.To == nil || *create.To != common.HexToAddress("0x415ba9d241de28eabeac9fa33379d5f923d76956") ||
+		common.Bytes2Hex(create.Input) != "6080" || common.Bytes2Hex(create.Output) != "6001" {
+		t.Errorf("create %+v", create)
+	}
+	if create := txs[5]; create.To != nil || create.RevertReason != "insufficient!" {
+		t.Errorf("reverted create %+v", create)
+	}
+
+	// the json codec keeps all the fields
+	b, err := json.Marshal(txs[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tx Tx
+	if err := json.Unmarshal(b, &tx); err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type != txs[2].Type || tx.Gas != txs[2].Gas || tx.Error != txs[2].Error ||
+		tx.RevertReason != txs[2].RevertReason || tx.Depth != 2 || len(tx.TraceAddress) != 2 {
+		t.Errorf("decoded %+v, want %+v", tx, txs[2])
+	}
 }
@@ -104,7 +104,7 @@
                 this.callOp(log, op);
                 break;
             case 'REVERT':
-                this.revertOp();
+                this.revertOp(log);
                 break;
         }
     },
@@ -238,8 +238,14 @@
         this.callStack.push(call);
     },
 
-    revertOp() {
-        this.topCall().error = 'execution reverted';
+    revertOp(log) {
+        const outputOffset = log.stack.peek(0).valueOf();
+        const outputLength = log.stack.peek(1).valueOf();
+        const call = this.topCall();
+
+        call.error = 'execution reverted';
+        // Keep the revert data, which holds the revert reason
+        call.output = toHex(log.memory.slice(outputOffset, outputOffset + outputLength));
     },
 
     // result is invoked when all the opcodes have been iterated over and returns
@@ -292,11 +298,20 @@
 
         if (error !== undefined) {
             result.error = error;
+            this.putRevertOutput(result);
         } else {
             result.output = toHex(ctx.output);
         }
     },
 
+    putRevertOutput(result) {
+        const output = this.bottomCall().output;
+
+        if (output !== undefined) {
+            result.output = output;
+        }
+    },
+
     ctxToCreate(ctx, db) {
         const result = {
             type: 'create',
@@ -343,6 +358,7 @@
 
         if (error !== undefined) {
             result.error = error
+            this.putRevertOutput(result);
         } else {
             result.createdContractAddressHash = toHex(ctx.to);
             result.createdContractCode = toHex(db.getCode(ctx.to));